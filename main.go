@@ -3,10 +3,18 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	usecases "todo_list_consumer/src/app/usecases"
 	taskUC "todo_list_consumer/src/app/usecases/task"
+	"todo_list_consumer/src/infra/clock"
 	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
 
 	"todo_list_consumer/src/interface/rest"
 
@@ -18,8 +26,12 @@ import (
 
 	"todo_list_consumer/src/infra/broker/nats"
 	taskNats "todo_list_consumer/src/infra/broker/nats/consumer/task"
+	"todo_list_consumer/src/infra/broker/nats/publisher"
 
-	scheduler "todo_list_consumer/src/infra/persistence/redis/scheduler"
+	memScheduler "todo_list_consumer/src/infra/persistence/memory/scheduler"
+	pgScheduler "todo_list_consumer/src/infra/persistence/postgres/scheduler"
+	rdScheduler "todo_list_consumer/src/infra/persistence/redis/scheduler"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
 
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/sirupsen/logrus"
@@ -28,8 +40,14 @@ import (
 )
 
 func main() {
-	// init context
-	ctx := context.Background()
+	// init context, dibatalkan saat menerima signal berhenti agar HTTP server dan worker ikut berhenti.
+	// kill (no param) default send syscall.SIGTERM, kill -2 is syscall.SIGINT,
+	// kill -9 is syscall.SIGKILL but can't be catch, so don't need add it
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer cancel()
+
+	// workers menunggu worker scheduler, reconciler dan archiver selesai sebelum koneksi database ditutup
+	var workers sync.WaitGroup
 
 	// read the server environment variables
 	conf := config.Make()
@@ -49,6 +67,34 @@ func main() {
 		ms_log.IsProduction(isProd),
 		ms_log.LogAdditionalFields(m))
 
+	// Zona default harus valid, jika tidak semua waktu tanpa offset akan ditolak
+	if _, err := time.LoadLocation(conf.Task.DefaultTimezone); err != nil {
+		logger.Fatalf("Invalid TASK_DEFAULT_TIMEZONE %q: %s", conf.Task.DefaultTimezone, err)
+	}
+
+	// Kebijakan subtask yang tidak dikenal akan diperlakukan sebagai cascade, tolak sejak awal
+	if p := conf.Task.ChildPolicy; p != taskConst.CHILD_POLICY_CASCADE && p != taskConst.CHILD_POLICY_DETACH {
+		logger.Fatalf("Invalid TASK_CHILD_POLICY %q: must be %s or %s", p, taskConst.CHILD_POLICY_CASCADE, taskConst.CHILD_POLICY_DETACH)
+	}
+
+	// Clock service. Dengan APP_TIME_TRAVEL waktu bisa digeser lewat endpoint /admin/clock
+	appClock := clock.New()
+	var timeTravel *clock.Offset
+	if conf.App.TimeTravel {
+		if isProd {
+			logger.Fatalf("APP_TIME_TRAVEL cannot be enabled in PRODUCTION")
+		}
+
+		// Endpoint /admin/clock hanya terdaftar jika endpoint admin aktif
+		if conf.Http.AdminToken == "" {
+			logger.Fatalf("APP_TIME_TRAVEL requires HTTP_ADMIN_TOKEN")
+		}
+
+		timeTravel = clock.NewOffset(appClock)
+		appClock = timeTravel
+		logger.Warn("Time travel enabled, service clock can be shifted through /admin/clock")
+	}
+
 	postgresdb, err := postgres.New(conf.SqlDb, logger)
 	if err != nil {
 		logger.Fatalf("Failed to initialize Postgres: %s", err)
@@ -63,28 +109,86 @@ func main() {
 		}
 	}(logger, postgresdb.Conn.DB, postgresdb.Conn.DriverName())
 
-	// Initialize Redis
-	redisClient, err := redis.NewRedisClient(conf.Redis, logger)
-	if err != nil {
-		logger.Fatalf("Failed to initialize Redis: %s", err)
+	taskRepository := taskRepo.NewTaskRepository(postgresdb.Conn, appClock)
+
+	// Pengecekan readiness tambahan dari dependency yang dipakai backend scheduler
+	readinessChecks := map[string]func() error{}
+
+	// Redis hanya dibutuhkan backend scheduler keyspace dan zset
+	var taskScheduler infra_scheduler.SchedulerInterface
+	switch conf.Scheduler.Backend {
+	case "postgres":
+		taskScheduler = pgScheduler.NewPostgresSchedulerService(postgresdb.Conn, appClock, conf.Scheduler)
+	case "memory":
+		logger.Warn("SCHEDULER_BACKEND=memory hanya untuk development, jadwal hilang saat restart dan tidak dibagi antar replica")
+		taskScheduler = memScheduler.NewMemorySchedulerService(appClock, conf.Scheduler)
+	case "keyspace", "zset":
+		redisClient, err := redis.NewRedisClient(conf.Redis, logger)
+		if err != nil {
+			logger.Fatalf("Failed to initialize Redis: %s", err)
+		}
+
+		if conf.Scheduler.Backend == "zset" {
+			taskScheduler = rdScheduler.NewZSetSchedulerService(redisClient, appClock, conf.Scheduler)
+		} else {
+			// Tanpa notify-keyspace-events Ex worker tidak pernah menerima event expired
+			if err := rdScheduler.EnsureKeyspaceEvents(ctx, redisClient, conf.Scheduler.KeyspaceEvents); err != nil {
+				logger.Fatalf("Redis keyspace notifications are not enabled: %s", err)
+			}
+
+			readinessChecks["keyspace_events"] = func() error {
+				checkCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+				defer cancel()
+				return rdScheduler.CheckKeyspaceEvents(checkCtx, redisClient)
+			}
+			taskScheduler = rdScheduler.NewBookingSchedulerService(redisClient, appClock, conf.Scheduler)
+		}
+	default:
+		logger.Fatalf("Unknown SCHEDULER_BACKEND %q", conf.Scheduler.Backend)
 	}
-	taskRepository := taskRepo.NewTaskRepository(postgresdb.Conn)
-	redisServe := scheduler.NewBookingSchedulerService(redisClient, taskRepository)
 	Nats := nats.NewNats(conf.Nats, logger)
+	natsPublisher := publisher.NewPublisher(Nats)
 
 	allUC := usecases.AllUseCases{
-		TaskUC: taskUC.NewTaskUseCase(taskRepository, redisServe),
+		TaskUC: taskUC.NewTaskUseCase(taskRepository, taskScheduler, natsPublisher, appClock, conf.Task),
 	}
 
+	// Worker scheduler meneruskan task yang expired ke use case
+	taskScheduler.RegisterHandler(allUC.TaskUC)
+
 	taskWorker := taskNats.NewTaskWorker(Nats, allUC.TaskUC)
 	_ = taskWorker
 
 	logger.Info("Task worker successfully started.")
 
-	// Start Redis Worker in a Goroutine
+	// Start Scheduler Worker in a Goroutine
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		logger.Println("Starting Scheduler Worker...")
+		taskScheduler.StartWorker(ctx)
+	}()
+
+	// Service baru siap jika worker scheduler sudah menerima jadwal
+	readinessChecks["scheduler"] = func() error {
+		if status := taskScheduler.Status(); status != infra_scheduler.WorkerSubscribed {
+			return fmt.Errorf("worker %s", status)
+		}
+		return nil
+	}
+
+	// Rekonsiliasi jadwal expire dengan task pending saat startup dan berkala
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		allUC.TaskUC.StartReconciler(ctx)
+	}()
+
+	// Job arsip task done/expired yang sudah lama
+	workers.Add(1)
 	go func() {
-		logger.Println("Starting Redis Worker...")
-		redisServe.StartWorker()
+		defer workers.Done()
+		allUC.TaskUC.StartArchiver(ctx)
 	}()
 
 	httpServer, err := rest.New(
@@ -92,10 +196,16 @@ func main() {
 		isProd,
 		logger,
 		allUC,
+		timeTravel,
+		readinessChecks,
 	)
 	if err != nil {
 		panic(err)
 	}
 	httpServer.Start(ctx)
 
+	// Start kembali setelah ctx dibatalkan, tunggu handler yang sedang berjalan di worker selesai
+	logger.Info("Waiting for workers to stop ...")
+	workers.Wait()
+	logger.Info("Workers stopped.")
 }
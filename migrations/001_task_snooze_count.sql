@@ -0,0 +1,2 @@
+-- Menyimpan berapa kali sebuah task sudah di-snooze
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS snooze_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,17 @@
+-- Definisi task berulang (RRULE). Setiap occurrence tetap disimpan sebagai baris di public.tasks
+CREATE TABLE IF NOT EXISTS public.task_series (
+	id         BIGSERIAL PRIMARY KEY,
+	user_id    BIGINT       NOT NULL,
+	title      VARCHAR(255) NOT NULL,
+	rrule      TEXT         NOT NULL,
+	timezone   VARCHAR(64)  NOT NULL,
+	dtstart    TIMESTAMPTZ  NOT NULL,
+	active     BOOLEAN      NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS series_id BIGINT REFERENCES public.task_series (id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_series_occurrence_idx
+	ON public.tasks (series_id, expires_at) WHERE series_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Subtask: parent_id menunjuk ke task induk. Subtask yang sudah selesai dilepas otomatis saat parent dihapus
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS parent_id BIGINT REFERENCES public.tasks (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_parent_id_idx ON public.tasks (parent_id);
@@ -0,0 +1,23 @@
+-- Prioritas dan deskripsi task
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'medium'
+	CHECK (priority IN ('low', 'medium', 'high', 'urgent'));
+
+-- Tag dinormalisasi ke tabel tersendiri
+CREATE TABLE IF NOT EXISTS public.tags (
+	id   BIGSERIAL PRIMARY KEY,
+	name VARCHAR(50) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS public.task_tags (
+	task_id BIGINT NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	tag_id  BIGINT NOT NULL REFERENCES public.tags (id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx ON public.task_tags (tag_id);
+
+-- Task berulang menyimpan atribut yang sama untuk setiap occurrence-nya
+ALTER TABLE public.task_series ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE public.task_series ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'medium';
+ALTER TABLE public.task_series ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,15 @@
+-- Task bisa ditugaskan ke user lain selain owner (user_id)
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS assignee_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS tasks_assignee_id_idx ON public.tasks (assignee_id);
+
+-- Kolaborator task beserta haknya
+CREATE TABLE IF NOT EXISTS public.task_members (
+	task_id    BIGINT      NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	user_id    BIGINT      NOT NULL,
+	permission VARCHAR(10) NOT NULL CHECK (permission IN ('view', 'complete')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_members_user_id_idx ON public.task_members (user_id);
@@ -0,0 +1,13 @@
+-- Dependency antar task: task_id tidak bisa diselesaikan sebelum blocked_by_id done
+CREATE TABLE IF NOT EXISTS public.task_dependencies (
+	task_id       BIGINT      NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	blocked_by_id BIGINT      NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (task_id, blocked_by_id),
+	CHECK (task_id <> blocked_by_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_dependencies_blocked_by_idx ON public.task_dependencies (blocked_by_id);
+
+-- Penanda task yang butuh perhatian, contoh: blocker-nya expired
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS flag_reason VARCHAR(50);
@@ -0,0 +1,14 @@
+-- Riwayat semua perubahan task. Sengaja tanpa foreign key agar riwayat task yang dihapus tetap ada
+CREATE TABLE IF NOT EXISTS public.task_events (
+	id         BIGSERIAL PRIMARY KEY,
+	task_id    BIGINT       NOT NULL,
+	event      VARCHAR(50)  NOT NULL,
+	actor_id   BIGINT,
+	source     VARCHAR(100) NOT NULL,
+	message_id VARCHAR(255),
+	old_value  JSONB        NOT NULL DEFAULT '{}',
+	new_value  JSONB        NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON public.task_events (task_id, created_at);
@@ -0,0 +1,13 @@
+-- Waktu pembuatan task, dipakai untuk menghitung rate pembuatan task per user
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS tasks_user_status_idx ON public.tasks (user_id, status);
+CREATE INDEX IF NOT EXISTS tasks_user_created_at_idx ON public.tasks (user_id, created_at);
+
+-- Override kuota per user. NULL berarti memakai default dari config, 0 berarti tanpa batas
+CREATE TABLE IF NOT EXISTS public.task_quotas (
+	user_id     BIGINT PRIMARY KEY,
+	max_pending INT CHECK (max_pending >= 0),
+	max_created INT CHECK (max_created >= 0),
+	updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,21 @@
+-- Arsip task done/expired yang sudah lama. Tag disimpan sebagai array karena relasi public.task_tags ikut terhapus
+CREATE TABLE IF NOT EXISTS public.tasks_archive (
+	id           BIGINT PRIMARY KEY,
+	user_id      BIGINT       NOT NULL,
+	assignee_id  BIGINT,
+	title        TEXT         NOT NULL,
+	description  TEXT         NOT NULL DEFAULT '',
+	priority     VARCHAR(10)  NOT NULL,
+	status       VARCHAR(20)  NOT NULL,
+	expires_at   TIMESTAMPTZ  NOT NULL,
+	snooze_count INT          NOT NULL DEFAULT 0,
+	series_id    BIGINT,
+	parent_id    BIGINT,
+	flag_reason  VARCHAR(50),
+	tags         TEXT[]       NOT NULL DEFAULT '{}',
+	created_at   TIMESTAMPTZ  NOT NULL,
+	archived_at  TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS tasks_archive_user_id_idx ON public.tasks_archive (user_id, expires_at);
+CREATE INDEX IF NOT EXISTS tasks_status_expires_at_idx ON public.tasks (status, expires_at);
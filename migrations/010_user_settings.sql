@@ -0,0 +1,6 @@
+-- Pengaturan per user. timezone adalah nama zona IANA, dipakai untuk waktu tanpa offset dari producer
+CREATE TABLE IF NOT EXISTS public.user_settings (
+	user_id    BIGINT PRIMARY KEY,
+	timezone   VARCHAR(64) NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
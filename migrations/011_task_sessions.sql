@@ -0,0 +1,13 @@
+-- Sesi kerja (time tracking) per task. Tanpa foreign key agar laporan tetap utuh setelah task diarsip atau dihapus
+CREATE TABLE IF NOT EXISTS public.task_sessions (
+	id         BIGSERIAL PRIMARY KEY,
+	task_id    BIGINT      NOT NULL,
+	user_id    BIGINT      NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	ended_at   TIMESTAMPTZ,
+	CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+-- Satu user hanya boleh punya satu sesi terbuka per task
+CREATE UNIQUE INDEX IF NOT EXISTS task_sessions_open_idx ON public.task_sessions (task_id, user_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS task_sessions_user_started_idx ON public.task_sessions (user_id, started_at);
@@ -0,0 +1,6 @@
+-- Kebijakan saat deadline task terlewati
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS expiry_policy VARCHAR(20) NOT NULL DEFAULT 'expire'
+	CHECK (expiry_policy IN ('expire', 'notify_only', 'auto_extend', 'grace'));
+
+-- Berapa kali deadline diperpanjang otomatis oleh kebijakan auto_extend
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS extend_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,18 @@
+-- Jadwal untuk backend scheduler postgres (SCHEDULER_BACKEND=postgres), tidak dipakai backend Redis.
+-- scheduled_at adalah waktu deadline task berikutnya diproses, NULL berarti tidak ada jadwal
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMPTZ;
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS schedule_attempts INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS tasks_scheduled_at_idx ON public.tasks (scheduled_at)
+	WHERE status = 'pending' AND scheduled_at IS NOT NULL;
+
+-- Reminder per offset sebelum deadline. Tanpa foreign key, reminder task yang sudah dihapus dibuang saat diproses
+CREATE TABLE IF NOT EXISTS public.task_reminders (
+	task_id        BIGINT      NOT NULL,
+	offset_seconds BIGINT      NOT NULL,
+	remind_at      TIMESTAMPTZ NOT NULL,
+	attempts       INT         NOT NULL DEFAULT 0,
+	PRIMARY KEY (task_id, offset_seconds)
+);
+
+CREATE INDEX IF NOT EXISTS task_reminders_remind_at_idx ON public.task_reminders (remind_at);
@@ -0,0 +1,3 @@
+-- Expiry yang dibatalkan lewat endpoint admin. Task tetap pending tanpa jadwal expire dan dilewati
+-- reconciler sampai deadline-nya dijadwalkan ulang
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS expiry_cancelled BOOLEAN NOT NULL DEFAULT FALSE;
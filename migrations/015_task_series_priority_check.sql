@@ -0,0 +1,6 @@
+-- Prioritas task series dibatasi sama seperti public.tasks agar occurrence tidak gagal dibuat
+UPDATE public.task_series SET priority = 'medium' WHERE priority NOT IN ('low', 'medium', 'high', 'urgent');
+
+ALTER TABLE public.task_series DROP CONSTRAINT IF EXISTS task_series_priority_check;
+ALTER TABLE public.task_series ADD CONSTRAINT task_series_priority_check
+	CHECK (priority IN ('low', 'medium', 'high', 'urgent'));
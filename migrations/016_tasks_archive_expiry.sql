@@ -0,0 +1,4 @@
+-- Kolom kebijakan expiry ikut diarsip agar task arsip sama dengan saat masih di public.tasks
+ALTER TABLE public.tasks_archive ADD COLUMN IF NOT EXISTS expiry_policy VARCHAR(20) NOT NULL DEFAULT 'expire';
+ALTER TABLE public.tasks_archive ADD COLUMN IF NOT EXISTS extend_count INT NOT NULL DEFAULT 0;
+ALTER TABLE public.tasks_archive ADD COLUMN IF NOT EXISTS expiry_cancelled BOOLEAN NOT NULL DEFAULT FALSE;
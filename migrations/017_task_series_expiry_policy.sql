@@ -0,0 +1,3 @@
+-- Kebijakan expiry task berulang, disalin ke setiap occurrence yang dibuat
+ALTER TABLE public.task_series ADD COLUMN IF NOT EXISTS expiry_policy VARCHAR(20) NOT NULL DEFAULT 'expire'
+	CHECK (expiry_policy IN ('expire', 'notify_only', 'auto_extend', 'grace'));
@@ -1,23 +1,459 @@
 package task
 
-import "time"
+import (
+	"time"
 
-// CreateTaskReqDTO digunakan untuk membuat task baru
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
+
+// Batasan field task yang divalidasi
+const (
+	MaxTitleLength       = 255
+	MaxDescriptionLength = 2000
+	MaxTagLength         = 50
+	MaxTagsPerTask       = 20
+	MaxDueLength         = 100
+)
+
+var expiryPolicies = []interface{}{
+	taskConst.EXPIRY_POLICY_EXPIRE,
+	taskConst.EXPIRY_POLICY_NOTIFY_ONLY,
+	taskConst.EXPIRY_POLICY_AUTO_EXTEND,
+	taskConst.EXPIRY_POLICY_GRACE,
+}
+
+var priorities = []interface{}{
+	taskConst.PRIORITY_LOW,
+	taskConst.PRIORITY_MEDIUM,
+	taskConst.PRIORITY_HIGH,
+	taskConst.PRIORITY_URGENT,
+}
+
+// EventMetaDTO menyimpan asal sebuah perubahan untuk dicatat di riwayat task.
+// Tidak dikirim oleh producer, diisi oleh consumer NATS, worker scheduler atau endpoint admin
+type EventMetaDTO struct {
+	Source    string // subject NATS, "scheduler" atau "admin"
+	MessageID string // header Nats-Msg-Id atau key Redis yang memicu perubahan
+}
+
+// CreateTaskReqDTO digunakan untuk membuat task baru.
+// Jika RRule diisi, task menjadi berulang dan ExpiresAt (opsional) dipakai sebagai DTSTART.
+// ExpiresAt tanpa offset dibaca di Timezone, timezone user, atau default config (urut prioritas).
+// Due adalah alternatif ExpiresAt berupa teks bebas, contoh "tomorrow 5pm" atau "besok jam 5 sore"
 type CreateTaskReqDTO struct {
-	UserID    int64     `json:"user_id"`
-	Title     string    `json:"title"`
-	ExpiresAt time.Time `json:"expires_at"`
+	UserID      int64     `json:"user_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Priority    string    `json:"priority"` // low, medium, high, urgent
+	Tags        []string  `json:"tags"`
+	ExpiresAt   Timestamp `json:"expires_at"`
+	Due         string    `json:"due"`
+	// ExpiryPolicy: expire (default), notify_only, auto_extend atau grace
+	ExpiryPolicy string  `json:"expiry_policy"`
+	RRule        string  `json:"rrule"`    // contoh: "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9"
+	Timezone     string  `json:"timezone"` // nama zona IANA, contoh: "Asia/Jakarta"
+	ParentID     *int64  `json:"parent_id"`
+	BlockedBy    []int64 `json:"blocked_by"` // id task lain yang harus selesai lebih dulu
+
+	Meta EventMetaDTO `json:"-"`
 }
 
-// UpdateTaskReqDTO digunakan untuk memperbarui task yang sudah ada
+func (dto *CreateTaskReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Title, validation.Required, validation.RuneLength(1, MaxTitleLength)),
+		validation.Field(&dto.Description, validation.RuneLength(0, MaxDescriptionLength)),
+		validation.Field(&dto.Priority, validation.In(priorities...)),
+		validation.Field(&dto.Tags, validation.Length(0, MaxTagsPerTask), validation.Each(validation.RuneLength(1, MaxTagLength))),
+		validation.Field(&dto.Due, validation.RuneLength(0, MaxDueLength)),
+		validation.Field(&dto.ExpiryPolicy, validation.In(expiryPolicies...)),
+	)
+}
+
+// UpdateTaskReqDTO digunakan untuk memperbarui task yang sudah ada.
+// UserID adalah user yang melakukan aksi, dipakai untuk pengecekan hak akses. Producer lama yang
+// belum mengirim user_id (0) tetap diterima tanpa pengecekan hak akses
 type FinishtTaskReqDTO struct {
-	ID int64 `json:"id"`
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *FinishtTaskReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+	)
 }
 
 type ExpireTaskReqDTO struct {
 	ID int64 `json:"id"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+// RemindTaskReqDTO dikirim worker scheduler ketika key reminder task expired
+type RemindTaskReqDTO struct {
+	ID     int64         `json:"id"`
+	Offset time.Duration `json:"offset"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+type DeleteTaskReqDTO struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *DeleteTaskReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+	)
+}
+
+// DependencyReqDTO dipakai untuk event adddependency dan removedependency
+type DependencyReqDTO struct {
+	ID        int64   `json:"id"`
+	UserID    int64   `json:"user_id"`
+	BlockedBy []int64 `json:"blocked_by"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *DependencyReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.BlockedBy, validation.Required),
+	)
+}
+
+// CollaboratorDTO adalah user lain yang ikut pada sebuah task beserta haknya (view atau complete)
+type CollaboratorDTO struct {
+	UserID     int64  `json:"user_id" db:"user_id"`
+	Permission string `json:"permission" db:"permission"`
+}
+
+func (dto CollaboratorDTO) Validate() error {
+	return validation.ValidateStruct(
+		&dto,
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Permission, validation.Required, validation.In(taskConst.PERMISSION_VIEW, taskConst.PERMISSION_COMPLETE)),
+	)
+}
+
+// AssignTaskReqDTO dipakai owner untuk menugaskan task ke user lain dan/atau
+// menambah serta menghapus kolaborator
+type AssignTaskReqDTO struct {
+	ID                  int64             `json:"id"`
+	UserID              int64             `json:"user_id"`     // owner yang melakukan assign
+	AssigneeID          *int64            `json:"assignee_id"` // kosongkan jika tidak diubah
+	Collaborators       []CollaboratorDTO `json:"collaborators"`
+	RemoveCollaborators []int64           `json:"remove_collaborators"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *AssignTaskReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Collaborators),
+	)
+}
+
+// SnoozeTaskReqDTO digunakan untuk memundurkan deadline task yang masih pending.
+// Isi salah satu: Duration (contoh "30m", "2h", "1d") atau Until (waktu absolut)
+type SnoozeTaskReqDTO struct {
+	ID       int64      `json:"id"`
+	UserID   int64      `json:"user_id"`
+	Duration string     `json:"duration"`
+	Until    *Timestamp `json:"until"`
+	Timezone string     `json:"timezone"` // dipakai jika Until tidak memiliki offset
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+// UpdateSeriesReqDTO mengubah aturan task berulang, field kosong berarti tidak diubah.
+// Perubahan hanya berlaku untuk occurrence berikutnya
+type UpdateSeriesReqDTO struct {
+	ID       int64  `json:"id"`
+	UserID   int64  `json:"user_id"`
+	Title    string `json:"title"`
+	RRule    string `json:"rrule"`
+	Timezone string `json:"timezone"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+// StopSeriesReqDTO menghentikan task berulang, occurrence yang sedang berjalan tidak disentuh
+type StopSeriesReqDTO struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+// TagsReqDTO dipakai untuk event addtags dan removetags
+type TagsReqDTO struct {
+	ID     int64    `json:"id"`
+	UserID int64    `json:"user_id"`
+	Tags   []string `json:"tags"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *TagsReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Tags, validation.Required, validation.Length(1, MaxTagsPerTask), validation.Each(validation.RuneLength(1, MaxTagLength))),
+	)
+}
+
+type ChangePriorityReqDTO struct {
+	ID       int64  `json:"id"`
+	UserID   int64  `json:"user_id"`
+	Priority string `json:"priority"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *ChangePriorityReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Priority, validation.Required, validation.In(priorities...)),
+	)
 }
 
 type CreateTaskRespDTO struct {
 	ID int64
 }
+
+// TaskDTO adalah snapshot satu baris task dari database
+type TaskDTO struct {
+	ID          int64          `json:"id" db:"id"`
+	UserID      int64          `json:"user_id" db:"user_id"`
+	AssigneeID  *int64         `json:"assignee_id" db:"assignee_id"`
+	Title       string         `json:"title" db:"title"`
+	Description string         `json:"description" db:"description"`
+	Priority    string         `json:"priority" db:"priority"`
+	Tags        pq.StringArray `json:"tags" db:"tags"`
+	Status      string         `json:"status" db:"status"`
+	ExpiresAt   time.Time      `json:"expires_at" db:"expires_at"`
+	SnoozeCount int            `json:"snooze_count" db:"snooze_count"`
+	SeriesID    *int64         `json:"series_id" db:"series_id"`
+	ParentID    *int64         `json:"parent_id" db:"parent_id"`
+	FlagReason  *string        `json:"flag_reason" db:"flag_reason"` // contoh: "blocker_expired"
+
+	ExpiryPolicy    string `json:"expiry_policy" db:"expiry_policy"`
+	ExtendCount     int    `json:"extend_count" db:"extend_count"`         // jumlah perpanjangan otomatis auto_extend
+	ExpiryCancelled bool   `json:"expiry_cancelled" db:"expiry_cancelled"` // expiry dibatalkan admin, task tidak akan di-expire
+	TrackedSeconds  int64  `json:"tracked_seconds" db:"tracked_seconds"`   // total sesi kerja, sesi terbuka dihitung sampai sekarang
+}
+
+// TaskEventDTO adalah payload event yang dikirim ke NATS ketika status task berubah.
+// Reason diisi untuk transisi otomatis, contoh: "children_done", "parent_expired"
+type TaskEventDTO struct {
+	Event            string    `json:"event"`
+	Reason           string    `json:"reason,omitempty"`
+	Task             *TaskDTO  `json:"task"`
+	NotifyUserIDs    []int64   `json:"notify_user_ids"`             // owner, assignee dan kolaborator task
+	RemainingSeconds int64     `json:"remaining_seconds,omitempty"` // diisi untuk event task.reminder
+	OffsetSeconds    int64     `json:"offset_seconds,omitempty"`    // offset reminder yang jatuh tempo, diisi untuk event task.reminder
+	OccurredAt       time.Time `json:"occurred_at"`
+}
+
+// QuotaUsageDTO berisi pemakaian kuota user beserta override dari public.task_quotas (nil jika tidak ada)
+type QuotaUsageDTO struct {
+	Pending    int  `db:"pending"`
+	Created    int  `db:"created"`
+	MaxPending *int `db:"max_pending"`
+	MaxCreated *int `db:"max_created"`
+}
+
+// PendingTaskDTO adalah ringkasan task pending yang dibaca reconciler. Overdue bernilai true jika
+// event overdue sudah dicatat untuk expires_at saat ini (kebijakan notify_only dan grace)
+type PendingTaskDTO struct {
+	ID           int64     `db:"id"`
+	ExpiresAt    time.Time `db:"expires_at"`
+	ExpiryPolicy string    `db:"expiry_policy"`
+	Overdue      bool      `db:"overdue"`
+}
+
+// ReconcileReportDTO berisi jumlah tindakan yang dilakukan satu putaran reconciler
+type ReconcileReportDTO struct {
+	Expired     int64 `json:"expired"`     // task pending yang deadline-nya terlewat dan diproses
+	Rescheduled int64 `json:"rescheduled"` // jadwal expiry yang hilang dan dibuat ulang
+	Orphaned    int64 `json:"orphaned"`    // jadwal expire atau reminder milik task yang sudah tidak ada atau tidak pending
+	Failed      int64 `json:"failed"`      // task yang gagal diproses, dicoba lagi di putaran berikutnya
+	Skipped     bool  `json:"skipped"`     // putaran dilewati karena reconciler sedang berjalan di replica lain
+}
+
+// TaskScheduleDTO adalah jadwal expire task menurut scheduler, dipakai endpoint admin.
+// ScheduledAt bisa berbeda dengan ExpiresAt, misalnya selama masa tenggang kebijakan grace
+type TaskScheduleDTO struct {
+	TaskID          int64      `json:"task_id"`
+	Status          string     `json:"status,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"` // deadline task di database
+	ExpiryCancelled bool       `json:"expiry_cancelled,omitempty"`
+	Scheduled       bool       `json:"scheduled"`
+	ScheduledAt     *time.Time `json:"scheduled_at,omitempty"` // waktu scheduler memproses deadline task
+	TTLSeconds      int64      `json:"ttl_seconds"`            // sisa waktu sampai ScheduledAt, 0 jika tidak terjadwal
+}
+
+// UpcomingExpirationsReqDTO adalah rentang waktu jadwal expire yang ingin dilihat, From dan To berformat RFC3339.
+// From kosong berarti sekarang, To kosong berarti satu jam setelah From, Limit 0 berarti 100
+type UpcomingExpirationsReqDTO struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Limit int    `json:"limit"`
+}
+
+func (dto *UpcomingExpirationsReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.From, validation.Date(time.RFC3339)),
+		validation.Field(&dto.To, validation.Date(time.RFC3339)),
+		validation.Field(&dto.Limit, validation.Min(0), validation.Max(1000)),
+	)
+}
+
+// RescheduleTaskReqDTO memindahkan deadline task pending lewat endpoint admin
+type RescheduleTaskReqDTO struct {
+	ID        int64     `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *RescheduleTaskReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.ExpiresAt, validation.Required),
+	)
+}
+
+// CancelExpiryReqDTO membatalkan expiry task pending lewat endpoint admin
+type CancelExpiryReqDTO struct {
+	ID int64 `json:"id"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+// TimerReqDTO memulai atau menghentikan sesi kerja user pada task
+type TimerReqDTO struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+
+	Meta EventMetaDTO `json:"-"`
+}
+
+func (dto *TimerReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+	)
+}
+
+// TimelineReqDTO meminta riwayat task, UserID adalah user pemanggil endpoint
+type TimelineReqDTO struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (dto *TimelineReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.ID, validation.Required),
+		validation.Field(&dto.UserID, validation.Required),
+	)
+}
+
+// TimeReportReqDTO meminta laporan waktu kerja user per hari, From dan To (inklusif) berformat 2006-01-02.
+// RequesterID adalah user pemanggil endpoint
+type TimeReportReqDTO struct {
+	UserID      int64  `json:"user_id"`
+	RequesterID int64  `json:"requester_id"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+}
+
+func (dto *TimeReportReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.RequesterID, validation.Required),
+		validation.Field(&dto.From, validation.Required, validation.Date("2006-01-02")),
+		validation.Field(&dto.To, validation.Required, validation.Date("2006-01-02")),
+	)
+}
+
+// TimeReportDTO adalah total waktu kerja user pada satu hari (di timezone user).
+// Sesi yang melewati tengah malam dihitung pada hari sesi dimulai
+type TimeReportDTO struct {
+	Day     string `json:"day" db:"day"` // format 2006-01-02
+	Seconds int64  `json:"seconds" db:"seconds"`
+}
+
+// SetTimezoneReqDTO menyimpan timezone default user
+type SetTimezoneReqDTO struct {
+	UserID   int64  `json:"user_id"`
+	Timezone string `json:"timezone"` // nama zona IANA, contoh: "Europe/Berlin"
+}
+
+func (dto *SetTimezoneReqDTO) Validate() error {
+	return validation.ValidateStruct(
+		dto,
+		validation.Field(&dto.UserID, validation.Required),
+		validation.Field(&dto.Timezone, validation.Required),
+	)
+}
+
+// TaskHistoryDTO adalah satu baris riwayat perubahan task di public.task_events
+type TaskHistoryDTO struct {
+	ID        int64          `json:"id" db:"id"`
+	TaskID    int64          `json:"task_id" db:"task_id"`
+	Event     string         `json:"event" db:"event"`
+	ActorID   *int64         `json:"actor_id" db:"actor_id"`
+	Source    string         `json:"source" db:"source"`
+	MessageID *string        `json:"message_id" db:"message_id"`
+	OldValue  types.JSONText `json:"old_value" db:"old_value"`
+	NewValue  types.JSONText `json:"new_value" db:"new_value"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
+
+// TaskSeriesDTO adalah definisi task berulang yang disimpan di public.task_series
+type TaskSeriesDTO struct {
+	ID          int64          `json:"id" db:"id"`
+	UserID      int64          `json:"user_id" db:"user_id"`
+	Title       string         `json:"title" db:"title"`
+	Description string         `json:"description" db:"description"`
+	Priority    string         `json:"priority" db:"priority"`
+	Tags        pq.StringArray `json:"tags" db:"tags"`
+	RRule       string         `json:"rrule" db:"rrule"`
+	Timezone    string         `json:"timezone" db:"timezone"`
+	DTStart     time.Time      `json:"dtstart" db:"dtstart"`
+	Active      bool           `json:"active" db:"active"`
+
+	// ExpiryPolicy disalin ke setiap occurrence
+	ExpiryPolicy string `json:"expiry_policy" db:"expiry_policy"`
+}
@@ -0,0 +1,54 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Format waktu tanpa offset yang diterima dari producer, diurutkan dari yang paling lengkap
+var floatingLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+}
+
+// Timestamp adalah waktu dari payload producer. Waktu dengan offset (RFC3339) dipakai apa adanya,
+// sedangkan waktu tanpa offset ditandai Floating dan dibaca sebagai jam dinding di timezone user
+type Timestamp struct {
+	time.Time
+	Floating bool
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Timestamp{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if value == "" {
+		*t = Timestamp{}
+		return nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		*t = Timestamp{Time: parsed}
+		return nil
+	}
+
+	for _, layout := range floatingLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			*t = Timestamp{Time: parsed, Floating: true}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("format waktu %q tidak dikenali", value)
+}
@@ -1,29 +1,288 @@
 package task
 
 import (
+	"database/sql"
 	"errors"
 	"log"
+	"time"
 	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/clock"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // TaskRepository mendefinisikan metode yang harus diimplementasikan
 
 type TaskRepository interface {
+	WithTransaction(fn func(r TaskRepository) error) error
+	WithReconcileLock(fn func() error) (bool, error)
 	AddTask(req *dto.CreateTaskReqDTO) (*dto.CreateTaskRespDTO, error)
 	FinishTask(req *dto.FinishtTaskReqDTO) error
 	ExpireTask(req *dto.ExpireTaskReqDTO) error
+	GetTask(id int64) (*dto.TaskDTO, error)
+	SnoozeTask(id int64, expiresAt time.Time, maxSnooze int) error
+	ExtendTask(id int64, expiresAt time.Time) error
+	RescheduleTask(id int64, expiresAt time.Time) error
+	CancelExpiry(id int64) error
+	AddSeries(series *dto.TaskSeriesDTO) (int64, error)
+	GetSeries(id int64) (*dto.TaskSeriesDTO, error)
+	UpdateSeries(series *dto.TaskSeriesDTO) error
+	StopSeries(id int64) error
+	GetLatestOccurrence(seriesID int64) (int64, error)
+	AddOccurrence(series *dto.TaskSeriesDTO, expiresAt time.Time) (*dto.CreateTaskRespDTO, error)
+	GetChildren(parentID int64) ([]dto.TaskDTO, error)
+	DeleteTask(id int64) error
+	DetachTask(id int64) error
+	AddTags(taskID int64, tags []string) error
+	RemoveTags(taskID int64, tags []string) error
+	ChangePriority(taskID int64, priority string) error
+	AssignTask(taskID int64, assigneeID int64) error
+	AddMembers(taskID int64, members []dto.CollaboratorDTO) error
+	RemoveMembers(taskID int64, userIDs []int64) error
+	GetMembers(taskID int64) ([]dto.CollaboratorDTO, error)
+	AddDependency(taskID int64, blockedByID int64) error
+	RemoveDependency(taskID int64, blockedByID int64) error
+	GetUnfinishedBlockers(taskID int64) ([]int64, error)
+	GetPendingDependents(blockerID int64) ([]int64, error)
+	FlagTask(taskID int64, reason string) error
+	AddHistory(history *dto.TaskHistoryDTO) error
+	GetHistory(taskID int64) ([]dto.TaskHistoryDTO, error)
+	GetQuotaUsage(userID int64, since time.Time) (*dto.QuotaUsageDTO, error)
+	ArchiveTasks(before time.Time, batchSize int, source string) (int64, error)
+	GetArchivedTask(id int64) (*dto.TaskDTO, error)
+	GetUserTimezone(userID int64) (string, error)
+	SetUserTimezone(userID int64, timezone string) error
+	StartSession(taskID int64, userID int64, at time.Time) error
+	StopSession(taskID int64, userID int64, at time.Time) error
+	CloseSessions(taskID int64, at time.Time) error
+	GetTimeReport(userID int64, timezone string, from time.Time, to time.Time) ([]dto.TimeReportDTO, error)
+	GetPendingTasks(afterID int64, limit int) ([]dto.PendingTaskDTO, error)
 }
 
+var (
+	// ErrTaskNotFound dikembalikan ketika task dengan id tertentu tidak ada
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrSeriesNotFound dikembalikan ketika task series dengan id tertentu tidak ada
+	ErrSeriesNotFound = errors.New("task series not found")
+	// ErrOccurrenceExists dikembalikan ketika occurrence pada waktu yang sama sudah pernah dibuat
+	ErrOccurrenceExists = errors.New("occurrence already exists")
+	// ErrDependencyCycle dikembalikan ketika dependency baru akan membentuk siklus
+	ErrDependencyCycle = errors.New("dependency cycle detected")
+	// ErrTaskNotPending dikembalikan ketika task yang akan diubah sudah tidak pending, misalnya sudah
+	// expired atau diselesaikan request lain
+	ErrTaskNotPending = errors.New("task not pending")
+	// ErrSessionOpen dikembalikan ketika user sudah memiliki sesi terbuka pada task
+	ErrSessionOpen = errors.New("session already open")
+	// ErrSessionNotOpen dikembalikan ketika user tidak memiliki sesi terbuka pada task
+	ErrSessionNotOpen = errors.New("no open session")
+)
+
+// dependencyLockKey adalah kunci advisory lock agar pengecekan siklus dan insert dependency
+// tidak balapan dengan insert dependency lain dari replica berbeda
+const dependencyLockKey = 7301
+
+// quotaLockKey adalah kunci advisory lock (bersama user_id) agar pengecekan kuota dan insert task
+// milik user yang sama dari beberapa replica tidak saling mendahului
+const quotaLockKey = 7302
+
+// reconcileLockKey adalah kunci advisory lock agar reconciler hanya berjalan di satu replica pada satu waktu
+const reconcileLockKey = 7303
+
+// Kolom task yang tersimpan, tag diambil dari tabel relasi public.task_tags
+const taskFields = `id, user_id, assignee_id, title, description, priority, status, expires_at, snooze_count, series_id, parent_id, flag_reason,
+	expiry_policy, extend_count, expiry_cancelled,
+	COALESCE((SELECT array_agg(tg.name ORDER BY tg.name) FROM public.task_tags tt
+		JOIN public.tags tg ON tg.id = tt.tag_id WHERE tt.task_id = tasks.id), '{}') AS tags`
+
+// Kolom yang dibaca ke dalam dto.TaskDTO, ditambah total sesi kerja task. Sesi terbuka dihitung sampai $2,
+// diisi waktu dari clock repository agar tidak bergantung pada now() Postgres
+const taskColumns = taskFields + `,
+	COALESCE((SELECT sum(EXTRACT(EPOCH FROM COALESCE(s.ended_at, $2) - s.started_at))::bigint
+		FROM public.task_sessions s WHERE s.task_id = tasks.id), 0) AS tracked_seconds`
+
 // Query SQL untuk berbagai operasi database
 const (
-	AddTask = `INSERT INTO public.tasks (user_id, title, expires_at)
-		VALUES ($1, $2, $3) Returning id`
+	AddTask = `INSERT INTO public.tasks (user_id, title, description, priority, expires_at, parent_id, expiry_policy, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) Returning id`
 
-	FinishTask = `UPDATE public.tasks SET status = 'done' WHERE id = $1;`
+	FinishTask = `UPDATE public.tasks SET status = 'done' WHERE id = $1 AND status = 'pending';`
 
 	ExpireTask = `UPDATE public.tasks SET status = 'expired' WHERE id = $1 AND status='pending';`
+
+	GetTask = `SELECT ` + taskColumns + ` FROM public.tasks WHERE id = $1`
+
+	GetChildren = `SELECT ` + taskColumns + ` FROM public.tasks WHERE parent_id = $1 ORDER BY id`
+
+	DeleteTask = `DELETE FROM public.tasks WHERE id = $1;`
+
+	DetachTask = `UPDATE public.tasks SET parent_id = NULL WHERE id = $1;`
+
+	UpsertTags = `INSERT INTO public.tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING;`
+
+	LinkTags = `INSERT INTO public.task_tags (task_id, tag_id)
+		SELECT $1, id FROM public.tags WHERE name = ANY($2::text[])
+		ON CONFLICT DO NOTHING;`
+
+	UnlinkTags = `DELETE FROM public.task_tags
+		WHERE task_id = $1 AND tag_id IN (SELECT id FROM public.tags WHERE name = ANY($2::text[]));`
+
+	ChangePriority = `UPDATE public.tasks SET priority = $2 WHERE id = $1;`
+
+	AssignTask = `UPDATE public.tasks SET assignee_id = $2 WHERE id = $1;`
+
+	AddMember = `INSERT INTO public.task_members (task_id, user_id, permission) VALUES ($1, $2, $3)
+		ON CONFLICT (task_id, user_id) DO UPDATE SET permission = EXCLUDED.permission;`
+
+	RemoveMembers = `DELETE FROM public.task_members WHERE task_id = $1 AND user_id = ANY($2::bigint[]);`
+
+	GetMembers = `SELECT user_id, permission FROM public.task_members WHERE task_id = $1 ORDER BY user_id`
+
+	// GetBlockedBy mengambil blocker langsung dari sekumpulan task, dipakai untuk menelusuri rantai blocked_by
+	GetBlockedBy = `SELECT DISTINCT blocked_by_id FROM public.task_dependencies WHERE task_id = ANY($1::bigint[])`
+
+	AddDependency = `INSERT INTO public.task_dependencies (task_id, blocked_by_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING;`
+
+	RemoveDependency = `DELETE FROM public.task_dependencies WHERE task_id = $1 AND blocked_by_id = $2;`
+
+	// Blocker yang expired tidak akan pernah done sehingga tidak lagi memblokir, dependent-nya diberi flag
+	GetUnfinishedBlockers = `SELECT t.id FROM public.task_dependencies d
+		JOIN public.tasks t ON t.id = d.blocked_by_id
+		WHERE d.task_id = $1 AND t.status = 'pending' ORDER BY t.id`
+
+	GetPendingDependents = `SELECT t.id FROM public.task_dependencies d
+		JOIN public.tasks t ON t.id = d.task_id
+		WHERE d.blocked_by_id = $1 AND t.status = 'pending' ORDER BY t.id`
+
+	FlagTask = `UPDATE public.tasks SET flag_reason = $2 WHERE id = $1;`
+
+	AddHistory = `INSERT INTO public.task_events (task_id, event, actor_id, source, message_id, old_value, new_value, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	GetHistory = `SELECT id, task_id, event, actor_id, source, message_id, old_value, new_value, created_at
+		FROM public.task_events WHERE task_id = $1 ORDER BY created_at, id`
+
+	// GetQuotaUsage menghitung task pending dan task yang dibuat sejak $2, beserta override kuota user
+	GetQuotaUsage = `SELECT
+			(SELECT count(*) FROM public.tasks WHERE user_id = $1 AND status = 'pending') AS pending,
+			(SELECT count(*) FROM public.tasks WHERE user_id = $1 AND created_at >= $2) AS created,
+			q.max_pending, q.max_created
+		FROM (SELECT 1) AS one LEFT JOIN public.task_quotas q ON q.user_id = $1`
+
+	// ArchiveTasks memindahkan satu batch task done/expired dengan expires_at sebelum $1 ke public.tasks_archive
+	// dan mencatat event "archived" di riwayatnya, semuanya dalam satu statement. Baris yang sedang dikunci
+	// replica lain dilewati (SKIP LOCKED). Task yang masih punya subtask atau dependent pending tidak diarsip
+	// agar relasinya tidak terlepas. Task disalin lebih dulu dan hanya id yang tersalin yang dihapus, arsip
+	// dengan id yang sama (misalnya dari percobaan sebelumnya) ditimpa dengan data terbaru.
+	//
+	// Relasi yang tidak ikut diarsip sengaja dilepas dan dicatat di riwayat: kolaborator (public.task_members)
+	// terhapus oleh foreign key dan disimpan di new_value event "archived" karena task arsip hanya bisa dibaca
+	// owner, sedangkan subtask done/expired yang tidak ikut batch kehilangan parent_id (ON DELETE SET NULL) dan
+	// mendapat event "detached". Edge dependency hanya tersisa ke task yang sudah tidak pending sehingga
+	// dibiarkan terhapus. Semua sub-statement membaca snapshot yang sama sehingga member dan subtask yang
+	// dicatat adalah keadaan sebelum DELETE. Query mengembalikan jumlah task yang dipindahkan
+	ArchiveTasks = `WITH batch AS (
+			SELECT t.id AS task_id FROM public.tasks t
+			WHERE t.status IN ('done', 'expired') AND t.expires_at < $1
+				AND NOT EXISTS (SELECT 1 FROM public.tasks c WHERE c.parent_id = t.id AND c.status = 'pending')
+				AND NOT EXISTS (SELECT 1 FROM public.task_dependencies d
+					JOIN public.tasks p ON p.id = d.task_id
+					WHERE d.blocked_by_id = t.id AND p.status = 'pending')
+			ORDER BY t.id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), archived AS (
+			INSERT INTO public.tasks_archive (` + archiveColumns + `, created_at, archived_at)
+			SELECT ` + taskFields + `, created_at, $4
+			FROM public.tasks JOIN batch ON batch.task_id = tasks.id
+			ON CONFLICT (id) DO UPDATE SET
+				user_id = EXCLUDED.user_id, assignee_id = EXCLUDED.assignee_id, title = EXCLUDED.title,
+				description = EXCLUDED.description, priority = EXCLUDED.priority, status = EXCLUDED.status,
+				expires_at = EXCLUDED.expires_at, snooze_count = EXCLUDED.snooze_count, series_id = EXCLUDED.series_id,
+				parent_id = EXCLUDED.parent_id, flag_reason = EXCLUDED.flag_reason, expiry_policy = EXCLUDED.expiry_policy,
+				extend_count = EXCLUDED.extend_count, expiry_cancelled = EXCLUDED.expiry_cancelled, tags = EXCLUDED.tags,
+				created_at = EXCLUDED.created_at, archived_at = EXCLUDED.archived_at
+			RETURNING id
+		), moved AS (
+			DELETE FROM public.tasks USING archived WHERE tasks.id = archived.id
+			RETURNING tasks.id
+		), recorded AS (
+			INSERT INTO public.task_events (task_id, event, source, old_value, new_value, created_at)
+			SELECT moved.id, 'archived', $3::text, '{}'::jsonb, jsonb_build_object('members', COALESCE(
+					(SELECT jsonb_agg(jsonb_build_object('user_id', m.user_id, 'permission', m.permission) ORDER BY m.user_id)
+					FROM public.task_members m WHERE m.task_id = moved.id), '[]')), $4::timestamptz
+			FROM moved
+			UNION ALL
+			SELECT c.id, 'detached', $3::text, jsonb_build_object('parent_id', c.parent_id),
+				jsonb_build_object('parent_id', NULL, 'reason', 'parent_archived'), $4::timestamptz
+			FROM public.tasks c JOIN moved ON c.parent_id = moved.id
+			WHERE c.id NOT IN (SELECT id FROM moved)
+		)
+		SELECT count(*) FROM moved`
+
+	// Urutan kolom mengikuti taskFields. Sesi kerja tidak ikut diarsip karena tetap tersimpan di public.task_sessions
+	archiveColumns = `id, user_id, assignee_id, title, description, priority, status, expires_at, snooze_count, series_id, parent_id, flag_reason,
+	expiry_policy, extend_count, expiry_cancelled, tags`
+
+	GetArchivedTask = `SELECT ` + archiveColumns + ` FROM public.tasks_archive WHERE id = $1`
+
+	GetUserTimezone = `SELECT timezone FROM public.user_settings WHERE user_id = $1`
+
+	SetUserTimezone = `INSERT INTO public.user_settings (user_id, timezone, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET timezone = EXCLUDED.timezone, updated_at = EXCLUDED.updated_at;`
+
+	StartSession = `INSERT INTO public.task_sessions (task_id, user_id, started_at) VALUES ($1, $2, $3)
+		ON CONFLICT (task_id, user_id) WHERE ended_at IS NULL DO NOTHING;`
+
+	StopSession = `UPDATE public.task_sessions SET ended_at = GREATEST($3, started_at)
+		WHERE task_id = $1 AND user_id = $2 AND ended_at IS NULL;`
+
+	CloseSessions = `UPDATE public.task_sessions SET ended_at = GREATEST($2, started_at)
+		WHERE task_id = $1 AND ended_at IS NULL;`
+
+	// GetTimeReport menjumlahkan sesi user per hari di timezone $2. Sesi terbuka dihitung sampai $5 (sekarang)
+	GetTimeReport = `SELECT to_char((started_at AT TIME ZONE $2)::date, 'YYYY-MM-DD') AS day,
+			sum(EXTRACT(EPOCH FROM COALESCE(ended_at, $5) - started_at))::bigint AS seconds
+		FROM public.task_sessions
+		WHERE user_id = $1 AND started_at >= $3 AND started_at < $4
+		GROUP BY 1 ORDER BY 1`
+
+	ExtendTask = `UPDATE public.tasks SET expires_at = $2, extend_count = extend_count + 1
+		WHERE id = $1 AND status = 'pending';`
+
+	RescheduleTask = `UPDATE public.tasks SET expires_at = $2, expiry_cancelled = FALSE
+		WHERE id = $1 AND status = 'pending';`
+
+	CancelExpiry = `UPDATE public.tasks SET expiry_cancelled = TRUE WHERE id = $1 AND status = 'pending';`
+
+	SnoozeTask = `UPDATE public.tasks SET expires_at = $2, snooze_count = snooze_count + 1, expiry_cancelled = FALSE
+		WHERE id = $1 AND status = 'pending' AND ($3 <= 0 OR snooze_count < $3);`
+
+	AddSeries = `INSERT INTO public.task_series (user_id, title, description, priority, tags, rrule, timezone, dtstart, expiry_policy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) Returning id`
+
+	GetSeries = `SELECT id, user_id, title, description, priority, tags, rrule, timezone, dtstart, active, expiry_policy
+		FROM public.task_series WHERE id = $1`
+
+	UpdateSeries = `UPDATE public.task_series SET title = $2, rrule = $3, timezone = $4, dtstart = $5, updated_at = $6
+		WHERE id = $1 AND active;`
+
+	StopSeries = `UPDATE public.task_series SET active = false, updated_at = $2 WHERE id = $1 AND active;`
+
+	GetLatestOccurrence = `SELECT id FROM public.tasks WHERE series_id = $1 ORDER BY expires_at DESC LIMIT 1`
+
+	// Keyset pagination berdasarkan id agar batch tetap konsisten walau ada task yang berubah status di tengah jalan
+	GetPendingTasks = `SELECT id, expires_at, expiry_policy,
+			EXISTS (SELECT 1 FROM public.task_events e WHERE e.task_id = tasks.id AND e.event = 'overdue'
+				AND (e.new_value->>'expires_at')::timestamptz = tasks.expires_at) AS overdue
+		FROM public.tasks WHERE status = 'pending' AND NOT expiry_cancelled AND id > $1 ORDER BY id LIMIT $2`
+
+	// Unique index (series_id, expires_at) mencegah occurrence ganda jika finish/expire diproses dua kali
+	AddOccurrence = `INSERT INTO public.tasks (user_id, title, description, priority, expires_at, series_id, created_at, expiry_policy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (series_id, expires_at) WHERE series_id IS NOT NULL DO NOTHING
+		Returning id`
 )
 
 // Struct untuk menyimpan statement yang telah diprepare
@@ -33,16 +292,71 @@ type PreparedStatement struct {
 	addTask    *sqlx.Stmt
 	finishTask *sqlx.Stmt
 	expireTask *sqlx.Stmt
+	getTask    *sqlx.Stmt
+	snoozeTask *sqlx.Stmt
+
+	addSeries     *sqlx.Stmt
+	getSeries     *sqlx.Stmt
+	updateSeries  *sqlx.Stmt
+	stopSeries    *sqlx.Stmt
+	addOccurrence *sqlx.Stmt
+	latestOccur   *sqlx.Stmt
+
+	getChildren *sqlx.Stmt
+	deleteTask  *sqlx.Stmt
+	detachTask  *sqlx.Stmt
+
+	upsertTags     *sqlx.Stmt
+	linkTags       *sqlx.Stmt
+	unlinkTags     *sqlx.Stmt
+	changePriority *sqlx.Stmt
+
+	assignTask    *sqlx.Stmt
+	addMember     *sqlx.Stmt
+	removeMembers *sqlx.Stmt
+	getMembers    *sqlx.Stmt
+
+	getBlockedBy          *sqlx.Stmt
+	addDependency         *sqlx.Stmt
+	removeDependency      *sqlx.Stmt
+	getUnfinishedBlockers *sqlx.Stmt
+	getPendingDependents  *sqlx.Stmt
+	flagTask              *sqlx.Stmt
+
+	addHistory *sqlx.Stmt
+	getHistory *sqlx.Stmt
+
+	getQuotaUsage *sqlx.Stmt
+
+	archiveTasks    *sqlx.Stmt
+	getArchivedTask *sqlx.Stmt
+
+	getUserTimezone *sqlx.Stmt
+	setUserTimezone *sqlx.Stmt
+
+	startSession  *sqlx.Stmt
+	stopSession   *sqlx.Stmt
+	closeSessions *sqlx.Stmt
+	getTimeReport *sqlx.Stmt
+
+	extendTask     *sqlx.Stmt
+	rescheduleTask *sqlx.Stmt
+	cancelExpiry   *sqlx.Stmt
+
+	getPendingTasks *sqlx.Stmt
 }
 
 type taskRepo struct {
 	Connection *sqlx.DB
+	tx         *sqlx.Tx    // terisi jika repository dipakai di dalam WithTransaction
+	clock      clock.Clock // sumber waktu untuk kolom created_at/updated_at
 }
 
 // NewUserRepository menginisialisasi UserRepo dan menyiapkan prepared statement
-func NewTaskRepository(db *sqlx.DB) TaskRepository {
+func NewTaskRepository(db *sqlx.DB, c clock.Clock) TaskRepository {
 	repo := &taskRepo{
 		Connection: db,
+		clock:      c,
 	}
 	InitPreparedStatement(repo)
 	return repo
@@ -64,14 +378,131 @@ func InitPreparedStatement(m *taskRepo) {
 		addTask:    m.Preparex(AddTask),
 		finishTask: m.Preparex(FinishTask),
 		expireTask: m.Preparex(ExpireTask),
+		getTask:    m.Preparex(GetTask),
+		snoozeTask: m.Preparex(SnoozeTask),
+
+		addSeries:     m.Preparex(AddSeries),
+		getSeries:     m.Preparex(GetSeries),
+		updateSeries:  m.Preparex(UpdateSeries),
+		stopSeries:    m.Preparex(StopSeries),
+		addOccurrence: m.Preparex(AddOccurrence),
+		latestOccur:   m.Preparex(GetLatestOccurrence),
+
+		getChildren: m.Preparex(GetChildren),
+		deleteTask:  m.Preparex(DeleteTask),
+		detachTask:  m.Preparex(DetachTask),
+
+		upsertTags:     m.Preparex(UpsertTags),
+		linkTags:       m.Preparex(LinkTags),
+		unlinkTags:     m.Preparex(UnlinkTags),
+		changePriority: m.Preparex(ChangePriority),
+
+		assignTask:    m.Preparex(AssignTask),
+		addMember:     m.Preparex(AddMember),
+		removeMembers: m.Preparex(RemoveMembers),
+		getMembers:    m.Preparex(GetMembers),
+
+		getBlockedBy:          m.Preparex(GetBlockedBy),
+		addDependency:         m.Preparex(AddDependency),
+		removeDependency:      m.Preparex(RemoveDependency),
+		getUnfinishedBlockers: m.Preparex(GetUnfinishedBlockers),
+		getPendingDependents:  m.Preparex(GetPendingDependents),
+		flagTask:              m.Preparex(FlagTask),
+
+		addHistory: m.Preparex(AddHistory),
+		getHistory: m.Preparex(GetHistory),
+
+		getQuotaUsage: m.Preparex(GetQuotaUsage),
+
+		archiveTasks:    m.Preparex(ArchiveTasks),
+		getArchivedTask: m.Preparex(GetArchivedTask),
+
+		getUserTimezone: m.Preparex(GetUserTimezone),
+		setUserTimezone: m.Preparex(SetUserTimezone),
+
+		startSession:  m.Preparex(StartSession),
+		stopSession:   m.Preparex(StopSession),
+		closeSessions: m.Preparex(CloseSessions),
+		getTimeReport: m.Preparex(GetTimeReport),
+
+		extendTask:     m.Preparex(ExtendTask),
+		rescheduleTask: m.Preparex(RescheduleTask),
+		cancelExpiry:   m.Preparex(CancelExpiry),
+
+		getPendingTasks: m.Preparex(GetPendingTasks),
 	}
 }
 
+// WithTransaction menjalankan fn dengan repository yang terikat ke satu transaksi.
+// Transaksi di-commit jika fn tidak mengembalikan error, selain itu di-rollback.
+// Pemanggilan bertingkat memakai transaksi yang sudah berjalan
+func (repo *taskRepo) WithTransaction(fn func(r TaskRepository) error) error {
+	return repo.transaction(func(r *taskRepo) error {
+		return fn(r)
+	})
+}
+
+func (repo *taskRepo) transaction(fn func(r *taskRepo) error) error {
+	if repo.tx != nil {
+		return fn(repo)
+	}
+
+	tx, err := repo.Connection.Beginx()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&taskRepo{Connection: repo.Connection, tx: tx, clock: repo.clock}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// WithReconcileLock menjalankan fn selama memegang advisory lock reconciler. Lock terikat pada transaksi
+// sehingga otomatis lepas saat fn selesai atau koneksi replica pemegangnya putus. Mengembalikan false
+// tanpa menjalankan fn jika lock sedang dipegang replica lain. fn tidak berjalan di dalam transaksi ini
+func (repo *taskRepo) WithReconcileLock(fn func() error) (bool, error) {
+	tx, err := repo.Connection.Beginx()
+	if err != nil {
+		log.Println(err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.Get(&locked, `SELECT pg_try_advisory_xact_lock($1)`, reconcileLockKey); err != nil {
+		log.Println(err)
+		return false, err
+	}
+
+	if !locked {
+		return false, nil
+	}
+
+	if err := fn(); err != nil {
+		return true, err
+	}
+
+	return true, tx.Commit()
+}
+
+// stmt mengikat prepared statement ke transaksi yang sedang berjalan (jika ada)
+func (repo *taskRepo) stmt(s *sqlx.Stmt) *sqlx.Stmt {
+	if repo.tx != nil {
+		return repo.tx.Stmtx(s)
+	}
+
+	return s
+}
+
 // RegisterUser menangani proses registrasi pengguna baru
 func (repo *taskRepo) AddTask(req *dto.CreateTaskReqDTO) (*dto.CreateTaskRespDTO, error) {
 
 	var resp dto.CreateTaskRespDTO
-	err := statement.addTask.QueryRow(req.UserID, req.Title, req.ExpiresAt).Scan(&resp.ID)
+	err := repo.stmt(statement.addTask).QueryRow(req.UserID, req.Title, req.Description, req.Priority, req.ExpiresAt.Time, req.ParentID, req.ExpiryPolicy, repo.clock.Now()).Scan(&resp.ID)
 
 	if err != nil {
 		log.Println(err)
@@ -81,28 +512,650 @@ func (repo *taskRepo) AddTask(req *dto.CreateTaskReqDTO) (*dto.CreateTaskRespDTO
 }
 
 // SignIn menangani autentikasi user berdasarkan email dan password
+// FinishTask menandai task pending sebagai done, ErrTaskNotPending jika task sudah tidak pending
 func (repo *taskRepo) FinishTask(req *dto.FinishtTaskReqDTO) error {
-	_, err := statement.finishTask.Exec(req.ID)
-
+	result, err := repo.stmt(statement.finishTask).Exec(req.ID)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrTaskNotPending
+	}
+
 	return nil
 }
 
 func (repo *taskRepo) ExpireTask(req *dto.ExpireTaskReqDTO) error {
-	result, err := statement.expireTask.Exec(req.ID)
+	result, err := repo.stmt(statement.expireTask).Exec(req.ID)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
 	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return errors.New("no rows affected")
+	}
+
+	return nil
+}
+
+// GetTask mengambil snapshot task berdasarkan id
+func (repo *taskRepo) GetTask(id int64) (*dto.TaskDTO, error) {
+	var task dto.TaskDTO
+	err := repo.stmt(statement.getTask).Get(&task, id, repo.clock.Now())
+
+	if err == sql.ErrNoRows {
+		return nil, ErrTaskNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// SnoozeTask memundurkan deadline task pending dan menaikkan snooze_count.
+// Update hanya terjadi jika task masih pending dan belum melewati batas maxSnooze
+func (repo *taskRepo) SnoozeTask(id int64, expiresAt time.Time, maxSnooze int) error {
+	result, err := repo.stmt(statement.snoozeTask).Exec(id, expiresAt, maxSnooze)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 
+	affected, _ := result.RowsAffected()
 	if affected == 0 {
 		return errors.New("no rows affected")
 	}
 
 	return nil
 }
+
+// ExtendTask memundurkan deadline task pending karena kebijakan auto_extend dan menaikkan extend_count
+func (repo *taskRepo) ExtendTask(id int64, expiresAt time.Time) error {
+	result, err := repo.stmt(statement.extendTask).Exec(id, expiresAt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return errors.New("no rows affected")
+	}
+
+	return nil
+}
+
+// RescheduleTask memindahkan deadline task pending lewat endpoint admin dan membatalkan CancelExpiry
+func (repo *taskRepo) RescheduleTask(id int64, expiresAt time.Time) error {
+	result, err := repo.stmt(statement.rescheduleTask).Exec(id, expiresAt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return errors.New("no rows affected")
+	}
+
+	return nil
+}
+
+// CancelExpiry menandai task pending agar tidak di-expire dan dilewati reconciler
+func (repo *taskRepo) CancelExpiry(id int64) error {
+	result, err := repo.stmt(statement.cancelExpiry).Exec(id)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return errors.New("no rows affected")
+	}
+
+	return nil
+}
+
+// AddSeries menyimpan definisi task berulang baru
+func (repo *taskRepo) AddSeries(series *dto.TaskSeriesDTO) (int64, error) {
+	var id int64
+	err := repo.stmt(statement.addSeries).QueryRow(series.UserID, series.Title, series.Description, series.Priority,
+		series.Tags, series.RRule, series.Timezone, series.DTStart, series.ExpiryPolicy).Scan(&id)
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetSeries mengambil definisi task berulang berdasarkan id
+func (repo *taskRepo) GetSeries(id int64) (*dto.TaskSeriesDTO, error) {
+	var series dto.TaskSeriesDTO
+	err := repo.stmt(statement.getSeries).Get(&series, id)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrSeriesNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// UpdateSeries memperbarui aturan task berulang yang masih aktif
+func (repo *taskRepo) UpdateSeries(series *dto.TaskSeriesDTO) error {
+	result, err := repo.stmt(statement.updateSeries).Exec(series.ID, series.Title, series.RRule, series.Timezone, series.DTStart, repo.clock.Now())
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrSeriesNotFound
+	}
+
+	return nil
+}
+
+// StopSeries menonaktifkan task berulang sehingga tidak ada occurrence baru
+func (repo *taskRepo) StopSeries(id int64) error {
+	result, err := repo.stmt(statement.stopSeries).Exec(id, repo.clock.Now())
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrSeriesNotFound
+	}
+
+	return nil
+}
+
+// GetLatestOccurrence mengambil id occurrence terakhir dari task berulang
+func (repo *taskRepo) GetLatestOccurrence(seriesID int64) (int64, error) {
+	var id int64
+	err := repo.stmt(statement.latestOccur).Get(&id, seriesID)
+
+	if err == sql.ErrNoRows {
+		return 0, ErrTaskNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// AddOccurrence membuat baris task baru untuk satu occurrence dari task berulang
+func (repo *taskRepo) AddOccurrence(series *dto.TaskSeriesDTO, expiresAt time.Time) (*dto.CreateTaskRespDTO, error) {
+	var resp dto.CreateTaskRespDTO
+	err := repo.stmt(statement.addOccurrence).QueryRow(series.UserID, series.Title, series.Description, series.Priority,
+		expiresAt, series.ID, repo.clock.Now(), series.ExpiryPolicy).Scan(&resp.ID)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrOccurrenceExists
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// GetChildren mengambil semua subtask langsung dari sebuah task
+func (repo *taskRepo) GetChildren(parentID int64) ([]dto.TaskDTO, error) {
+	children := []dto.TaskDTO{}
+	err := repo.stmt(statement.getChildren).Select(&children, parentID, repo.clock.Now())
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return children, nil
+}
+
+// DeleteTask menghapus task. Subtask yang masih menunjuk ke task ini akan dilepas oleh foreign key
+func (repo *taskRepo) DeleteTask(id int64) error {
+	result, err := repo.stmt(statement.deleteTask).Exec(id)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// DetachTask melepas subtask dari parent-nya
+func (repo *taskRepo) DetachTask(id int64) error {
+	_, err := repo.stmt(statement.detachTask).Exec(id)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// AddTags menambahkan tag ke task. Tag baru otomatis dibuat di public.tags
+func (repo *taskRepo) AddTags(taskID int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err := repo.stmt(statement.upsertTags).Exec(pq.Array(tags))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	_, err = repo.stmt(statement.linkTags).Exec(taskID, pq.Array(tags))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// RemoveTags melepas tag dari task, baris di public.tags tetap disimpan
+func (repo *taskRepo) RemoveTags(taskID int64, tags []string) error {
+	_, err := repo.stmt(statement.unlinkTags).Exec(taskID, pq.Array(tags))
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// ChangePriority mengubah prioritas task
+func (repo *taskRepo) ChangePriority(taskID int64, priority string) error {
+	result, err := repo.stmt(statement.changePriority).Exec(taskID, priority)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// AssignTask menugaskan task ke user lain
+func (repo *taskRepo) AssignTask(taskID int64, assigneeID int64) error {
+	result, err := repo.stmt(statement.assignTask).Exec(taskID, assigneeID)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// AddMembers menambahkan kolaborator, permission kolaborator yang sudah ada akan diperbarui
+func (repo *taskRepo) AddMembers(taskID int64, members []dto.CollaboratorDTO) error {
+	for _, member := range members {
+		_, err := repo.stmt(statement.addMember).Exec(taskID, member.UserID, member.Permission)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveMembers menghapus kolaborator dari task
+func (repo *taskRepo) RemoveMembers(taskID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := repo.stmt(statement.removeMembers).Exec(taskID, pq.Array(userIDs))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetMembers mengambil semua kolaborator task
+func (repo *taskRepo) GetMembers(taskID int64) ([]dto.CollaboratorDTO, error) {
+	members := []dto.CollaboratorDTO{}
+	err := repo.stmt(statement.getMembers).Select(&members, taskID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// AddDependency mencatat bahwa taskID diblokir oleh blockedByID. Pengecekan siklus dan insert
+// dijalankan dalam satu transaksi dengan advisory lock
+func (repo *taskRepo) AddDependency(taskID int64, blockedByID int64) error {
+	if taskID == blockedByID {
+		return ErrDependencyCycle
+	}
+
+	return repo.transaction(func(r *taskRepo) error {
+		if _, err := r.tx.Exec(`SELECT pg_advisory_xact_lock($1)`, dependencyLockKey); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		cycle, err := createsCycle(taskID, blockedByID, func(ids []int64) ([]int64, error) {
+			blockers := []int64{}
+			err := r.stmt(statement.getBlockedBy).Select(&blockers, pq.Array(ids))
+			return blockers, err
+		})
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if cycle {
+			return ErrDependencyCycle
+		}
+
+		if _, err := r.stmt(statement.addDependency).Exec(taskID, blockedByID); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// createsCycle menelusuri rantai blocked_by dari calon blocker per level. Edge taskID -> blockedByID
+// membentuk siklus jika taskID bisa dicapai dari blockedByID. blockedBy mengembalikan blocker langsung
+// dari sekumpulan task
+func createsCycle(taskID int64, blockedByID int64, blockedBy func(ids []int64) ([]int64, error)) (bool, error) {
+	if taskID == blockedByID {
+		return true, nil
+	}
+
+	visited := map[int64]bool{blockedByID: true}
+	frontier := []int64{blockedByID}
+	for len(frontier) > 0 {
+		blockers, err := blockedBy(frontier)
+		if err != nil {
+			return false, err
+		}
+
+		frontier = nil
+		for _, id := range blockers {
+			if id == taskID {
+				return true, nil
+			}
+
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// RemoveDependency menghapus edge dependency
+func (repo *taskRepo) RemoveDependency(taskID int64, blockedByID int64) error {
+	_, err := repo.stmt(statement.removeDependency).Exec(taskID, blockedByID)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetUnfinishedBlockers mengambil id blocker yang masih pending
+func (repo *taskRepo) GetUnfinishedBlockers(taskID int64) ([]int64, error) {
+	ids := []int64{}
+	err := repo.stmt(statement.getUnfinishedBlockers).Select(&ids, taskID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetPendingDependents mengambil id task pending yang diblokir oleh blockerID
+func (repo *taskRepo) GetPendingDependents(blockerID int64) ([]int64, error) {
+	ids := []int64{}
+	err := repo.stmt(statement.getPendingDependents).Select(&ids, blockerID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// FlagTask menandai task dengan alasan tertentu, contoh: blocker-nya expired
+func (repo *taskRepo) FlagTask(taskID int64, reason string) error {
+	_, err := repo.stmt(statement.flagTask).Exec(taskID, reason)
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// AddHistory mencatat satu perubahan task ke public.task_events
+func (repo *taskRepo) AddHistory(history *dto.TaskHistoryDTO) error {
+	_, err := repo.stmt(statement.addHistory).Exec(history.TaskID, history.Event, history.ActorID, history.Source,
+		history.MessageID, history.OldValue, history.NewValue, repo.clock.Now())
+
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetHistory mengambil riwayat perubahan task urut dari yang paling lama
+func (repo *taskRepo) GetHistory(taskID int64) ([]dto.TaskHistoryDTO, error) {
+	history := []dto.TaskHistoryDTO{}
+	err := repo.stmt(statement.getHistory).Select(&history, taskID)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// GetQuotaUsage mengambil pemakaian kuota user. Di dalam transaksi, pemanggilan untuk user yang sama
+// dari transaksi lain menunggu sampai transaksi ini selesai sehingga kuota tidak terlewati
+func (repo *taskRepo) GetQuotaUsage(userID int64, since time.Time) (*dto.QuotaUsageDTO, error) {
+	if repo.tx != nil {
+		if _, err := repo.tx.Exec(`SELECT pg_advisory_xact_lock($1, ($2::bigint % 2147483647)::int)`, quotaLockKey, userID); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+	}
+
+	usage := &dto.QuotaUsageDTO{}
+	err := repo.stmt(statement.getQuotaUsage).Get(usage, userID, since)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// ArchiveTasks memindahkan satu batch task lama ke arsip dan mengembalikan jumlah task yang dipindahkan
+func (repo *taskRepo) ArchiveTasks(before time.Time, batchSize int, source string) (int64, error) {
+	var moved int64
+	err := repo.stmt(statement.archiveTasks).QueryRow(before, batchSize, source, repo.clock.Now()).Scan(&moved)
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+// GetArchivedTask mengambil task dari public.tasks_archive
+func (repo *taskRepo) GetArchivedTask(id int64) (*dto.TaskDTO, error) {
+	var task dto.TaskDTO
+	err := repo.stmt(statement.getArchivedTask).Get(&task, id)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrTaskNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// GetUserTimezone mengambil timezone user, string kosong jika user belum mengaturnya
+func (repo *taskRepo) GetUserTimezone(userID int64) (string, error) {
+	var timezone string
+	err := repo.stmt(statement.getUserTimezone).Get(&timezone, userID)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+
+	return timezone, nil
+}
+
+// SetUserTimezone menyimpan timezone user
+func (repo *taskRepo) SetUserTimezone(userID int64, timezone string) error {
+	_, err := repo.stmt(statement.setUserTimezone).Exec(userID, timezone, repo.clock.Now())
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// StartSession membuka sesi kerja user pada task
+func (repo *taskRepo) StartSession(taskID int64, userID int64, at time.Time) error {
+	result, err := repo.stmt(statement.startSession).Exec(taskID, userID, at)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrSessionOpen
+	}
+
+	return nil
+}
+
+// StopSession menutup sesi kerja user yang masih terbuka pada task
+func (repo *taskRepo) StopSession(taskID int64, userID int64, at time.Time) error {
+	result, err := repo.stmt(statement.stopSession).Exec(taskID, userID, at)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return ErrSessionNotOpen
+	}
+
+	return nil
+}
+
+// CloseSessions menutup semua sesi terbuka pada task, dipakai saat task selesai, expired atau dihapus
+func (repo *taskRepo) CloseSessions(taskID int64, at time.Time) error {
+	_, err := repo.stmt(statement.closeSessions).Exec(taskID, at)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetTimeReport mengambil total waktu kerja user per hari dalam rentang [from, to)
+func (repo *taskRepo) GetTimeReport(userID int64, timezone string, from time.Time, to time.Time) ([]dto.TimeReportDTO, error) {
+	report := []dto.TimeReportDTO{}
+	err := repo.stmt(statement.getTimeReport).Select(&report, userID, timezone, from, to, repo.clock.Now())
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetPendingTasks mengambil task pending dengan id lebih besar dari afterID, urut berdasarkan id
+func (repo *taskRepo) GetPendingTasks(afterID int64, limit int) ([]dto.PendingTaskDTO, error) {
+	tasks := []dto.PendingTaskDTO{}
+	err := repo.stmt(statement.getPendingTasks).Select(&tasks, afterID, limit)
+
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,54 @@
+package task
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatesCycle(t *testing.T) {
+	// edges: task_id -> blocked_by_id
+	edges := map[int64][]int64{
+		1: {2},
+		2: {3},
+		3: {4},
+		5: {2, 3},
+	}
+	blockedBy := func(ids []int64) ([]int64, error) {
+		blockers := []int64{}
+		for _, id := range ids {
+			blockers = append(blockers, edges[id]...)
+		}
+		return blockers, nil
+	}
+
+	tests := []struct {
+		name      string
+		taskID    int64
+		blockedBy int64
+		cycle     bool
+	}{
+		{"diri sendiri", 1, 1, true},
+		{"siklus langsung", 2, 1, true},
+		{"siklus transitif", 4, 1, true},
+		{"siklus lewat percabangan", 4, 5, true},
+		{"rantai searah", 1, 4, false},
+		{"diamond tanpa siklus", 6, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycle, err := createsCycle(tt.taskID, tt.blockedBy, blockedBy)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.cycle, cycle)
+		})
+	}
+}
+
+func TestCreatesCycleQueryError(t *testing.T) {
+	_, err := createsCycle(1, 2, func(ids []int64) ([]int64, error) {
+		return nil, errors.New("koneksi putus")
+	})
+	assert.Error(t, err)
+}
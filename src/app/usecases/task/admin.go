@@ -0,0 +1,178 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// reasonForced dikirim di event task.expired ketika admin meng-expire task secara paksa
+const reasonForced = "forced"
+
+// Rentang default daftar jadwal expire di endpoint admin
+const (
+	upcomingDefaultWindow = time.Hour
+	upcomingDefaultLimit  = 100
+)
+
+// GetTaskSchedule mengembalikan deadline task di database beserta jadwal expire-nya di scheduler
+func (uc *taskUseCase) GetTaskSchedule(taskID int64) (*dto.TaskScheduleDTO, error) {
+	task, err := uc.getTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	at, scheduled, err := uc.Scheduler.ScheduledExpiry(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := taskSchedule(task.ID, at, scheduled, uc.Clock.Now())
+	schedule.Status = task.Status
+	schedule.ExpiresAt = &task.ExpiresAt
+	schedule.ExpiryCancelled = task.ExpiryCancelled
+
+	return &schedule, nil
+}
+
+// ListUpcomingExpirations mengembalikan jadwal expire di scheduler dalam rentang waktu, urut dari yang terdekat
+func (uc *taskUseCase) ListUpcomingExpirations(req *dto.UpcomingExpirationsReqDTO) ([]dto.TaskScheduleDTO, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	now := uc.Clock.Now()
+
+	from := now
+	if req.From != "" {
+		from, _ = time.Parse(time.RFC3339, req.From)
+	}
+
+	to := from.Add(upcomingDefaultWindow)
+	if req.To != "" {
+		to, _ = time.Parse(time.RFC3339, req.To)
+	}
+
+	if to.Before(from) {
+		return nil, infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("to harus sama atau setelah from"))
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = upcomingDefaultLimit
+	}
+
+	expirations, err := uc.Scheduler.UpcomingExpirations(from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]dto.TaskScheduleDTO, 0, len(expirations))
+	for _, expiration := range expirations {
+		schedules = append(schedules, taskSchedule(expiration.TaskID, expiration.ExpiresAt, true, now))
+	}
+
+	return schedules, nil
+}
+
+// ForceExpireTask meng-expire task pending saat ini juga tanpa menunggu deadline dan tanpa
+// menjalankan kebijakan expiry-nya
+func (uc *taskUseCase) ForceExpireTask(req *dto.ExpireTaskReqDTO) error {
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status != "pending" {
+		return infra_errors.NewError(infra_errors.TASK_NOT_PENDING, errors.New("task sudah tidak pending"))
+	}
+
+	return uc.expireTask(task.ID, reasonForced, req.Meta)
+}
+
+// CancelTaskExpiry menghapus jadwal expire dan reminder task pending. Task ditandai agar reconciler
+// tidak membuat jadwalnya ulang sampai deadline-nya dijadwalkan ulang
+func (uc *taskUseCase) CancelTaskExpiry(req *dto.CancelExpiryReqDTO) error {
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status != "pending" {
+		return infra_errors.NewError(infra_errors.TASK_NOT_PENDING, errors.New("task sudah tidak pending"))
+	}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.CancelExpiry(task.ID); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyExpiryCancelled, 0, req.Meta, fields{"expires_at": task.ExpiresAt}, nil)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	uc.cancelSchedule(task.ID)
+
+	return nil
+}
+
+// RescheduleTask memindahkan deadline task pending ke waktu tertentu tanpa menaikkan snooze_count,
+// termasuk task yang expiry-nya dibatalkan
+func (uc *taskUseCase) RescheduleTask(req *dto.RescheduleTaskReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	if !req.ExpiresAt.After(uc.Clock.Now()) {
+		return infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("expires_at harus di masa depan"))
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if task.Status != "pending" {
+		return infra_errors.NewError(infra_errors.TASK_NOT_PENDING, errors.New("task sudah tidak pending"))
+	}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.RescheduleTask(task.ID, req.ExpiresAt); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyRescheduled, 0, req.Meta,
+			fields{"expires_at": task.ExpiresAt, "expiry_cancelled": task.ExpiryCancelled},
+			fields{"expires_at": req.ExpiresAt})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	uc.reschedule(task.ID, req.ExpiresAt)
+
+	return nil
+}
+
+// taskSchedule membentuk jadwal expire beserta sisa waktunya dari now
+func taskSchedule(taskID int64, at time.Time, scheduled bool, now time.Time) dto.TaskScheduleDTO {
+	schedule := dto.TaskScheduleDTO{TaskID: taskID, Scheduled: scheduled}
+	if !scheduled {
+		return schedule
+	}
+
+	schedule.ScheduledAt = &at
+	if ttl := at.Sub(now); ttl > 0 {
+		schedule.TTLSeconds = int64(ttl.Round(time.Second) / time.Second)
+	}
+
+	return schedule
+}
@@ -0,0 +1,108 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskSchedule(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+	schedule := taskSchedule(1, now.Add(90*time.Second), true, now)
+	assert.True(t, schedule.Scheduled)
+	assert.Equal(t, now.Add(90*time.Second), *schedule.ScheduledAt)
+	assert.Equal(t, int64(90), schedule.TTLSeconds)
+
+	// Jadwal yang sudah lewat tetapi belum diproses worker
+	schedule = taskSchedule(1, now.Add(-time.Minute), true, now)
+	assert.Equal(t, int64(0), schedule.TTLSeconds)
+
+	schedule = taskSchedule(1, time.Time{}, false, now)
+	assert.False(t, schedule.Scheduled)
+	assert.Nil(t, schedule.ScheduledAt)
+}
+
+func TestForceExpireTask(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	meta := dto.EventMetaDTO{Source: taskConst.SOURCE_ADMIN}
+
+	r := newFakeRepo(
+		dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour)},
+		dto.TaskDTO{ID: 2, UserID: 7, ExpiresAt: now.Add(time.Hour), Status: "done"},
+	)
+	uc, s, p, _ := newTestUseCase(r, config.TaskConf{})
+	s.expiries[1] = now.Add(time.Hour)
+	s.reminders[1] = now.Add(time.Hour)
+
+	// Deadline belum lewat tetapi task tetap di-expire
+	assert.NoError(t, uc.ForceExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+	assert.Equal(t, "expired", r.tasks[1].Status)
+	assert.Equal(t, []string{historyExpired}, r.events(1))
+	assert.Empty(t, s.expiries)
+	assert.Empty(t, s.reminders)
+	if assert.Len(t, p.payloads, 1) {
+		assert.Equal(t, reasonForced, p.payloads[0].Reason)
+	}
+
+	err := uc.ForceExpireTask(&dto.ExpireTaskReqDTO{ID: 2, Meta: meta})
+	assert.Equal(t, infra_errors.TASK_NOT_PENDING, errorCode(err))
+
+	err = uc.ForceExpireTask(&dto.ExpireTaskReqDTO{ID: 3, Meta: meta})
+	assert.Equal(t, infra_errors.TASK_NOT_FOUND, errorCode(err))
+}
+
+func TestCancelTaskExpiry(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	meta := dto.EventMetaDTO{Source: taskConst.SOURCE_ADMIN}
+
+	r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour)})
+	uc, s, _, c := newTestUseCase(r, config.TaskConf{ReconcileBatchSize: 10})
+	s.expiries[1] = now.Add(time.Hour)
+	s.reminders[1] = now.Add(time.Hour)
+
+	assert.NoError(t, uc.CancelTaskExpiry(&dto.CancelExpiryReqDTO{ID: 1, Meta: meta}))
+	assert.True(t, r.tasks[1].ExpiryCancelled)
+	assert.Equal(t, []string{historyExpiryCancelled}, r.events(1))
+	assert.Empty(t, s.expiries)
+	assert.Empty(t, s.reminders)
+
+	// Jadwal yang terlanjur diproses worker tidak meng-expire task
+	c.Advance(2 * time.Hour)
+	assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: dto.EventMetaDTO{Source: taskConst.SOURCE_SCHEDULER}}))
+	assert.Equal(t, "pending", r.tasks[1].Status)
+
+	// Reconciler tidak membuat jadwalnya ulang maupun meng-expire task
+	report, err := uc.ReconcileTasks()
+	assert.NoError(t, err)
+	assert.Equal(t, &dto.ReconcileReportDTO{}, report)
+	assert.Equal(t, "pending", r.tasks[1].Status)
+	assert.Empty(t, s.expiries)
+	assert.Equal(t, []string{historyExpiryCancelled}, r.events(1))
+}
+
+func TestRescheduleTask(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	meta := dto.EventMetaDTO{Source: taskConst.SOURCE_ADMIN}
+
+	r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour), ExpiryCancelled: true, SnoozeCount: 1})
+	uc, s, _, _ := newTestUseCase(r, config.TaskConf{})
+
+	err := uc.RescheduleTask(&dto.RescheduleTaskReqDTO{ID: 1, ExpiresAt: now.Add(-time.Minute), Meta: meta})
+	assert.Equal(t, infra_errors.DATA_INVALID, errorCode(err))
+
+	// Task yang expiry-nya dibatalkan kembali dijadwalkan tanpa menaikkan snooze_count
+	assert.NoError(t, uc.RescheduleTask(&dto.RescheduleTaskReqDTO{ID: 1, ExpiresAt: now.Add(3 * time.Hour), Meta: meta}))
+	assert.False(t, r.tasks[1].ExpiryCancelled)
+	assert.Equal(t, now.Add(3*time.Hour), r.tasks[1].ExpiresAt)
+	assert.Equal(t, 1, r.tasks[1].SnoozeCount)
+	assert.Equal(t, now.Add(3*time.Hour), s.expiries[1])
+	assert.Equal(t, now.Add(3*time.Hour), s.reminders[1])
+	assert.Equal(t, []string{historyRescheduled}, r.events(1))
+}
@@ -0,0 +1,62 @@
+package task
+
+import (
+	"context"
+	"log"
+	"time"
+
+	taskConst "todo_list_consumer/src/infra/constants"
+)
+
+// ArchiveTasks memindahkan task done/expired yang lebih tua dari ArchiveAfter ke arsip, batch demi batch
+// sampai habis. Aman dijalankan di beberapa replica sekaligus karena tiap batch melewati baris yang
+// sedang diproses replica lain. Arsip hanya disimpan di public.tasks_archive agar tetap bisa dibaca lewat
+// repository, ekspor ke file JSONL tidak didukung
+func (uc *taskUseCase) ArchiveTasks() (int64, error) {
+	if uc.Conf.ArchiveAfter <= 0 {
+		return 0, nil
+	}
+
+	before := uc.Clock.Now().Add(-uc.Conf.ArchiveAfter)
+
+	var total int64
+	for {
+		moved, err := uc.Repo.ArchiveTasks(before, uc.Conf.ArchiveBatchSize, taskConst.SOURCE_ARCHIVER)
+		total += moved
+		if err != nil {
+			return total, err
+		}
+
+		if moved < int64(uc.Conf.ArchiveBatchSize) {
+			return total, nil
+		}
+	}
+}
+
+// StartArchiver menjalankan ArchiveTasks setiap ArchiveInterval sampai ctx dibatalkan
+func (uc *taskUseCase) StartArchiver(ctx context.Context) {
+	if uc.Conf.ArchiveAfter <= 0 {
+		log.Println("Arsip task dimatikan")
+		return
+	}
+
+	ticker := time.NewTicker(uc.Conf.ArchiveInterval)
+	defer ticker.Stop()
+
+	for {
+		total, err := uc.ArchiveTasks()
+		if err != nil {
+			log.Println("Gagal mengarsipkan task:", err)
+		}
+
+		if total > 0 {
+			log.Printf("%d task berhasil diarsipkan", total)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRepo mencatat hasil setiap pemanggilan ArchiveTasks dan bisa gagal pada pemanggilan tertentu
+type batchRepo struct {
+	*fakeRepo
+	batches []int64
+	failAt  int // nomor pemanggilan (mulai 1) yang gagal, 0 berarti tidak pernah gagal
+}
+
+func (b *batchRepo) ArchiveTasks(before time.Time, batchSize int, source string) (int64, error) {
+	if len(b.batches)+1 == b.failAt {
+		return 0, errors.New("db down")
+	}
+
+	moved, err := b.fakeRepo.ArchiveTasks(before, batchSize, source)
+	b.batches = append(b.batches, moved)
+	return moved, err
+}
+
+func TestArchiveTasksSelection(t *testing.T) {
+	old := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	recent := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		tasks    []dto.TaskDTO
+		blockers map[int64][]int64
+		archived []int64
+		kept     []int64
+	}{
+		{"done dan expired yang lama", []dto.TaskDTO{
+			{ID: 1, Status: "done", ExpiresAt: old},
+			{ID: 2, Status: "expired", ExpiresAt: old},
+		}, nil, []int64{1, 2}, nil},
+		{"pending dan task baru tidak diarsip", []dto.TaskDTO{
+			{ID: 1, ExpiresAt: old},
+			{ID: 2, Status: "done", ExpiresAt: recent},
+		}, nil, nil, []int64{1, 2}},
+		{"masih punya subtask pending", []dto.TaskDTO{
+			{ID: 1, Status: "done", ExpiresAt: old},
+			{ID: 2, ParentID: int64Ptr(1), ExpiresAt: recent},
+		}, nil, nil, []int64{1, 2}},
+		{"masih punya dependent pending", []dto.TaskDTO{
+			{ID: 1, Status: "expired", ExpiresAt: old},
+			{ID: 2, ExpiresAt: recent},
+		}, map[int64][]int64{2: {1}}, nil, []int64{1, 2}},
+		{"subtask done yang baru tetap tinggal", []dto.TaskDTO{
+			{ID: 1, Status: "done", ExpiresAt: old},
+			{ID: 2, Status: "done", ParentID: int64Ptr(1), ExpiresAt: recent},
+		}, nil, []int64{1}, []int64{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(tt.tasks...)
+			if tt.blockers != nil {
+				r.blockers = tt.blockers
+			}
+			uc, _, _, _ := newTestUseCase(r, config.TaskConf{ArchiveAfter: 30 * 24 * time.Hour, ArchiveBatchSize: 10})
+
+			total, err := uc.ArchiveTasks()
+			assert.NoError(t, err)
+			assert.Equal(t, int64(len(tt.archived)), total)
+
+			for _, id := range tt.archived {
+				assert.NotContains(t, r.tasks, id)
+				assert.Contains(t, r.archived, id)
+				assert.Equal(t, []string{historyArchived}, r.events(id))
+			}
+			for _, id := range tt.kept {
+				assert.Contains(t, r.tasks, id)
+				assert.NotContains(t, r.archived, id)
+			}
+		})
+	}
+}
+
+func TestArchiveTasksRecordsDroppedRelations(t *testing.T) {
+	old := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := newFakeRepo(
+		dto.TaskDTO{ID: 1, UserID: 7, Status: "done", ExpiresAt: old, Tags: []string{"kerja"}},
+		dto.TaskDTO{ID: 2, UserID: 7, Status: "done", ParentID: int64Ptr(1), ExpiresAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)},
+	)
+	r.members[1] = []dto.CollaboratorDTO{{UserID: 9, Permission: taskConst.PERMISSION_COMPLETE}}
+	// Arsip lama dengan id yang sama (misalnya dari percobaan sebelumnya) ditimpa
+	r.archived[1] = &dto.TaskDTO{ID: 1, UserID: 7, Title: "versi lama", Status: "pending"}
+	uc, _, _, _ := newTestUseCase(r, config.TaskConf{ArchiveAfter: 30 * 24 * time.Hour, ArchiveBatchSize: 10})
+
+	total, err := uc.ArchiveTasks()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	archived, err := r.GetArchivedTask(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "done", archived.Status)
+	assert.Equal(t, []string{"kerja"}, []string(archived.Tags))
+
+	// Kolaborator terhapus bersama task dan tersimpan di event archived
+	assert.Empty(t, r.members[1])
+	history, _ := r.GetHistory(1)
+	assert.Equal(t, taskConst.SOURCE_ARCHIVER, history[0].Source)
+	assert.JSONEq(t, `{"members":[{"user_id":9,"permission":"complete"}]}`, string(history[0].NewValue))
+
+	// Subtask yang tidak ikut diarsip kehilangan parent_id dengan event detached
+	assert.Nil(t, r.tasks[2].ParentID)
+	assert.Equal(t, []string{historyDetached}, r.events(2))
+	history, _ = r.GetHistory(2)
+	assert.JSONEq(t, `{"parent_id":1}`, string(history[0].OldValue))
+	assert.JSONEq(t, `{"parent_id":null,"reason":"parent_archived"}`, string(history[0].NewValue))
+}
+
+func TestArchiveTasksBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		eligible  int
+		batchSize int
+		failAt    int
+		batches   []int64
+		total     int64
+		err       bool
+	}{
+		{"batch terakhir tidak penuh", 5, 2, 0, []int64{2, 2, 1}, 5, false},
+		{"batch terakhir kosong", 4, 2, 0, []int64{2, 2, 0}, 4, false},
+		{"tidak ada yang diarsip", 0, 2, 0, []int64{0}, 0, false},
+		{"gagal di tengah jalan", 5, 2, 2, []int64{2}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := []dto.TaskDTO{}
+			for i := 1; i <= tt.eligible; i++ {
+				tasks = append(tasks, dto.TaskDTO{ID: int64(i), Status: "done", ExpiresAt: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)})
+			}
+			r := &batchRepo{fakeRepo: newFakeRepo(tasks...), failAt: tt.failAt}
+			uc, _, _, _ := newTestUseCase(r.fakeRepo, config.TaskConf{ArchiveAfter: 30 * 24 * time.Hour, ArchiveBatchSize: tt.batchSize})
+			uc.Repo = r
+
+			total, err := uc.ArchiveTasks()
+			assert.Equal(t, tt.err, err != nil)
+			assert.Equal(t, tt.total, total)
+			assert.Equal(t, tt.batches, r.batches)
+			assert.Len(t, r.archived, int(tt.total))
+		})
+	}
+}
+
+func TestArchiveTasksDisabled(t *testing.T) {
+	r := &batchRepo{fakeRepo: newFakeRepo(dto.TaskDTO{ID: 1, Status: "done"})}
+	uc, _, _, _ := newTestUseCase(r.fakeRepo, config.TaskConf{ArchiveBatchSize: 10})
+	uc.Repo = r
+
+	total, err := uc.ArchiveTasks()
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, r.batches)
+}
@@ -0,0 +1,138 @@
+package task
+
+import (
+	"fmt"
+	"log"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+const reasonBlockerExpired = "blocker_expired"
+
+// AddDependency menambahkan blocker ke task. Dependency yang membentuk siklus ditolak
+func (uc *taskUseCase) AddDependency(req *dto.DependencyReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	for _, blockerID := range req.BlockedBy {
+		blocker, err := uc.getTask(blockerID)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.authorize(blocker, req.UserID); err != nil {
+			return err
+		}
+	}
+
+	return uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		for _, blockerID := range req.BlockedBy {
+			if err := addDependency(r, task.ID, blockerID); err != nil {
+				return err
+			}
+
+			if err := record(r, task.ID, historyDependencyAdded, req.UserID, req.Meta, nil, fields{"blocked_by": blockerID}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RemoveDependency menghapus blocker dari task
+func (uc *taskUseCase) RemoveDependency(req *dto.DependencyReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	return uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		for _, blockerID := range req.BlockedBy {
+			if err := r.RemoveDependency(task.ID, blockerID); err != nil {
+				return err
+			}
+
+			if err := record(r, task.ID, historyDependencyRemoved, req.UserID, req.Meta, fields{"blocked_by": blockerID}, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// addDependency menyimpan satu dependency memakai r agar bisa ikut transaksi pemanggil
+func addDependency(r repo.TaskRepository, taskID int64, blockerID int64) error {
+	err := r.AddDependency(taskID, blockerID)
+	if err == repo.ErrDependencyCycle {
+		return infra_errors.NewError(infra_errors.DEPENDENCY_CYCLE, err)
+	}
+
+	return err
+}
+
+// checkBlockers menolak penyelesaian task yang masih memiliki blocker pending. Blocker yang expired
+// atau dihapus tidak menahan dependent-nya selamanya
+func (uc *taskUseCase) checkBlockers(taskID int64) error {
+	blockers, err := uc.Repo.GetUnfinishedBlockers(taskID)
+	if err != nil {
+		return err
+	}
+
+	if len(blockers) > 0 {
+		return infra_errors.NewError(infra_errors.TASK_BLOCKED, fmt.Errorf("task masih diblokir oleh task %v", blockers))
+	}
+
+	return nil
+}
+
+// flagDependents menandai task pending yang diblokir oleh task yang baru saja expired. Blocker yang
+// expired tidak lagi memblokir (lihat checkBlockers), flag memberi tahu user bahwa pekerjaan yang
+// ditunggu tidak pernah selesai sebelum dependent-nya diselesaikan
+func (uc *taskUseCase) flagDependents(blockerID int64, meta dto.EventMetaDTO) {
+	dependents, err := uc.Repo.GetPendingDependents(blockerID)
+	if err != nil {
+		log.Println("Gagal mengambil task yang bergantung:", err)
+		return
+	}
+
+	for _, id := range dependents {
+		err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+			if err := r.FlagTask(id, reasonBlockerExpired); err != nil {
+				return err
+			}
+
+			return record(r, id, historyFlagged, 0, meta, nil, fields{"flag_reason": reasonBlockerExpired, "blocker_id": blockerID})
+		})
+
+		if err != nil {
+			log.Println("Gagal menandai task:", err)
+			continue
+		}
+
+		uc.notifyTransition(taskConst.TASK_FLAGGED_EVENT, reasonBlockerExpired, id)
+	}
+}
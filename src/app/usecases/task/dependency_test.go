@@ -0,0 +1,38 @@
+package task
+
+import (
+	"testing"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiredBlockerReleasesDependents(t *testing.T) {
+	r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7}, dto.TaskDTO{ID: 2, UserID: 7}, dto.TaskDTO{ID: 3, UserID: 7})
+	r.blockers[2] = []int64{1}
+	r.blockers[3] = []int64{1}
+	uc, _, p, _ := newTestUseCase(r, config.TaskConf{})
+
+	// Selama blocker pending, dependent tidak bisa diselesaikan
+	err := uc.FinishTask(&dto.FinishtTaskReqDTO{ID: 2, UserID: 7})
+	assert.Equal(t, infra_errors.TASK_BLOCKED, errorCode(err))
+
+	assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1}))
+	assert.Equal(t, "expired", r.tasks[1].Status)
+
+	for _, id := range []int64{2, 3} {
+		if assert.NotNil(t, r.tasks[id].FlagReason) {
+			assert.Equal(t, reasonBlockerExpired, *r.tasks[id].FlagReason)
+		}
+		assert.Equal(t, []string{historyFlagged}, r.events(id))
+	}
+	assert.Equal(t, []string{taskConst.TASK_FLAGGED_EVENT, taskConst.TASK_FLAGGED_EVENT}, p.subjects)
+
+	// Blocker yang expired tidak lagi menahan dependent
+	assert.NoError(t, uc.FinishTask(&dto.FinishtTaskReqDTO{ID: 2, UserID: 7}))
+	assert.Equal(t, "done", r.tasks[2].Status)
+}
@@ -0,0 +1,231 @@
+package task
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Jam default untuk due yang hanya menyebut tanggal ("besok", "friday"): akhir hari
+const dueEndOfDayHour, dueEndOfDayMinute = 23, 59
+
+var (
+	// "in 3 days", "in 2 hours", "dalam 30 menit"
+	dueRelativeIn = regexp.MustCompile(`^(?:in|dalam) (\d+) ([a-z]+)$`)
+	// "3 hari lagi", "2 jam lagi"
+	dueRelativeLagi = regexp.MustCompile(`^(\d+) ([a-z]+) lagi$`)
+	// "5", "5pm", "5:30 pm", "17.00", "jam 5 sore", "at 9am"
+	dueClock = regexp.MustCompile(`^(?:(?:at|jam|pukul) )?(\d{1,2})(?:[:.](\d{2}))? ?(am|pm|pagi|siang|sore|malam)?$`)
+)
+
+var dueUnits = map[string]time.Duration{
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute, "menit": time.Minute,
+	"hour": time.Hour, "hours": time.Hour, "jam": time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour, "hari": 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour, "minggu": 7 * 24 * time.Hour,
+}
+
+var dueWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"minggu": time.Sunday, "senin": time.Monday, "selasa": time.Tuesday, "rabu": time.Wednesday,
+	"kamis": time.Thursday, "jumat": time.Friday, "sabtu": time.Saturday,
+}
+
+// Frasa yang sengaja ditolak karena bisa berarti lebih dari satu tanggal
+var dueAmbiguous = map[string]string{
+	"next week":    "next week tidak menyebut hari",
+	"minggu depan": "minggu depan bisa berarti pekan depan atau hari Minggu depan",
+	"this weekend": "weekend tidak menyebut hari",
+	"akhir pekan":  "akhir pekan tidak menyebut hari",
+}
+
+// parseDue mengubah teks bebas berbahasa Inggris atau Indonesia menjadi waktu absolut.
+// now harus sudah berada di zona waktu user. Contoh yang diterima: "tomorrow 5pm", "in 3 days",
+// "monday 9am", "besok jam 5 sore", "3 hari lagi", "senin jam 09.00". Nama hari berarti hari tersebut
+// yang terdekat setelah hari ini. Tanggal tanpa jam berarti akhir hari (23:59), jam tanpa tanggal
+// berarti hari ini. Input yang ambigu ditolak, contoh: "jam 5" (pagi atau sore?), "next monday"
+// (Senin terdekat atau pekan berikutnya?) atau "5pm" ketika jam 5 sore hari ini sudah lewat
+func parseDue(text string, now time.Time) (time.Time, error) {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if text == "" {
+		return time.Time{}, errors.New("due kosong")
+	}
+
+	for phrase, reason := range dueAmbiguous {
+		if strings.HasPrefix(text, phrase) {
+			return time.Time{}, errors.New("due ambigu: " + reason)
+		}
+	}
+
+	if words := strings.Fields(text); len(words) > 1 && words[0] == "next" {
+		if _, ok := dueWeekdays[words[1]]; ok {
+			return time.Time{}, errors.New("due ambigu: next " + words[1] + " bisa berarti hari terdekat atau pekan berikutnya, sebutkan nama harinya saja")
+		}
+	}
+
+	if m := dueRelativeIn.FindStringSubmatch(text); m != nil {
+		return dueRelative(m[1], m[2], now)
+	}
+
+	if m := dueRelativeLagi.FindStringSubmatch(text); m != nil {
+		return dueRelative(m[1], m[2], now)
+	}
+
+	date, rest, hasDate := dueDate(text, now)
+
+	hour, minute := dueEndOfDayHour, dueEndOfDayMinute
+	hasClock := rest != ""
+	if hasClock {
+		var err error
+		hour, minute, err = dueClockTime(rest)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	if !hasDate && !hasClock {
+		return time.Time{}, errors.New("format due tidak dikenali: " + text)
+	}
+
+	due := wallClock(time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, time.UTC), now.Location())
+	if !due.After(now) {
+		if !hasDate {
+			return time.Time{}, errors.New("due ambigu: jam tersebut hari ini sudah lewat, sebutkan harinya")
+		}
+		return time.Time{}, errors.New("due sudah lewat")
+	}
+
+	return due, nil
+}
+
+// dueRelative menghitung "n satuan" dari sekarang
+func dueRelative(amount string, unit string, now time.Time) (time.Time, error) {
+	n, err := strconv.Atoi(amount)
+	if err != nil || n <= 0 {
+		return time.Time{}, errors.New("jumlah waktu pada due tidak valid")
+	}
+
+	d, ok := dueUnits[unit]
+	if !ok {
+		return time.Time{}, errors.New("satuan waktu pada due tidak dikenali: " + unit)
+	}
+
+	// Satuan hari dan minggu mengikuti kalender agar jam dinding tetap sama saat DST
+	if d >= 24*time.Hour {
+		days := n * int(d/(24*time.Hour))
+		return now.AddDate(0, 0, days), nil
+	}
+
+	return now.Add(time.Duration(n) * d), nil
+}
+
+// dueDate membaca kata tanggal di awal teks dan mengembalikan sisa teks (bagian jam).
+// hasDate false berarti teks tidak diawali kata tanggal dan tanggalnya hari ini
+func dueDate(text string, now time.Time) (time.Time, string, bool) {
+	words := strings.Fields(text)
+
+	switch {
+	case strings.HasPrefix(text, "today"):
+		return now, strings.TrimSpace(strings.TrimPrefix(text, "today")), true
+	case strings.HasPrefix(text, "hari ini"):
+		return now, strings.TrimSpace(strings.TrimPrefix(text, "hari ini")), true
+	case strings.HasPrefix(text, "tomorrow"):
+		return now.AddDate(0, 0, 1), strings.TrimSpace(strings.TrimPrefix(text, "tomorrow")), true
+	case strings.HasPrefix(text, "besok"):
+		return now.AddDate(0, 0, 1), strings.TrimSpace(strings.TrimPrefix(text, "besok")), true
+	case strings.HasPrefix(text, "day after tomorrow"):
+		return now.AddDate(0, 0, 2), strings.TrimSpace(strings.TrimPrefix(text, "day after tomorrow")), true
+	case strings.HasPrefix(text, "lusa"):
+		return now.AddDate(0, 0, 2), strings.TrimSpace(strings.TrimPrefix(text, "lusa")), true
+	}
+
+	// "monday", "on monday", "hari senin", "senin depan"
+	i := 0
+	if i < len(words) && (words[i] == "hari" || words[i] == "on") {
+		i++
+	}
+
+	if i < len(words) {
+		if weekday, ok := dueWeekdays[words[i]]; ok {
+			i++
+			if i < len(words) && words[i] == "depan" {
+				i++
+			}
+
+			days := (int(weekday) - int(now.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+
+			return now.AddDate(0, 0, days), strings.Join(words[i:], " "), true
+		}
+	}
+
+	return now, text, false
+}
+
+// dueClockTime membaca jam. Jam 1-12 tanpa am/pm atau keterangan pagi/siang/sore/malam ditolak
+// karena ambigu, kecuali ditulis dua digit ("09:00") atau jam 12 dengan menit ("12:30")
+func dueClockTime(text string) (int, int, error) {
+	text = strings.TrimPrefix(text, "at ")
+
+	m := dueClock.FindStringSubmatch(text)
+	if m == nil {
+		return 0, 0, errors.New("format jam pada due tidak dikenali: " + text)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, errors.New("jam pada due tidak valid: " + text)
+	}
+
+	period := m[3]
+	if period == "" {
+		twentyFour := hour == 0 || hour > 12 || strings.HasPrefix(m[1], "0") || (hour == 12 && m[2] != "")
+		if !twentyFour {
+			return 0, 0, errors.New("due ambigu: sebutkan am/pm atau pagi/siang/sore/malam untuk jam " + m[1])
+		}
+		return hour, minute, nil
+	}
+
+	if hour == 0 || hour > 12 {
+		return 0, 0, errors.New("jam pada due tidak valid: " + text)
+	}
+
+	switch period {
+	case "am", "pagi":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour != 12 {
+			hour += 12
+		}
+	case "siang":
+		// jam 11 siang dan 12 siang tetap, jam 1-3 siang menjadi 13-15
+		if hour < 11 {
+			hour += 12
+		}
+	case "sore":
+		if hour == 12 {
+			return 0, 0, errors.New("due ambigu: jam 12 sore")
+		}
+		hour += 12
+	case "malam":
+		// jam 12 malam atau 1 malam bisa jatuh di hari berikutnya, hanya jam 6-11 malam yang diterima
+		if hour < 6 || hour == 12 {
+			return 0, 0, errors.New("due ambigu: jam " + m[1] + " malam")
+		}
+		hour += 12
+	}
+
+	return hour, minute, nil
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDue(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, loc) // Rabu 10:00 WIB
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"tomorrow 5pm", time.Date(2025, 3, 13, 17, 0, 0, 0, loc)},
+		{"Tomorrow at 9:30 am", time.Date(2025, 3, 13, 9, 30, 0, 0, loc)},
+		{"in 3 days", time.Date(2025, 3, 15, 10, 0, 0, 0, loc)},
+		{"in 90 minutes", time.Date(2025, 3, 12, 11, 30, 0, 0, loc)},
+		{"monday 9am", time.Date(2025, 3, 17, 9, 0, 0, 0, loc)},
+		{"on wednesday", time.Date(2025, 3, 19, 23, 59, 0, 0, loc)},
+		{"friday", time.Date(2025, 3, 14, 23, 59, 0, 0, loc)},
+		{"today 17:00", time.Date(2025, 3, 12, 17, 0, 0, 0, loc)},
+		{"besok jam 5 sore", time.Date(2025, 3, 13, 17, 0, 0, 0, loc)},
+		{"besok", time.Date(2025, 3, 13, 23, 59, 0, 0, loc)},
+		{"3 hari lagi", time.Date(2025, 3, 15, 10, 0, 0, 0, loc)},
+		{"2 jam lagi", time.Date(2025, 3, 12, 12, 0, 0, 0, loc)},
+		{"lusa jam 08.00", time.Date(2025, 3, 14, 8, 0, 0, 0, loc)},
+		{"senin depan jam 9 pagi", time.Date(2025, 3, 17, 9, 0, 0, 0, loc)},
+		{"hari rabu jam 2 siang", time.Date(2025, 3, 19, 14, 0, 0, 0, loc)},
+		{"jam 7 malam", time.Date(2025, 3, 12, 19, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			due, err := parseDue(tt.text, now)
+			if assert.NoError(t, err) {
+				assert.True(t, tt.want.Equal(due), "want %s, got %s", tt.want, due)
+			}
+		})
+	}
+}
+
+func TestParseDueRejectsAmbiguous(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, loc)
+
+	for _, text := range []string{
+		"besok jam 5", // pagi atau sore?
+		"tomorrow 5",  // am atau pm?
+		"9am",         // jam 9 pagi hari ini sudah lewat
+		"next week",   // hari apa?
+		"next monday", // Senin terdekat atau pekan berikutnya?
+		"next friday 5pm",
+		"minggu depan", // pekan depan atau hari Minggu?
+		"jam 12 malam", // hari ini atau besok?
+		"someday",      // tidak dikenali
+		"in 3 fortnights",
+	} {
+		_, err := parseDue(text, now)
+		assert.Error(t, err, text)
+	}
+}
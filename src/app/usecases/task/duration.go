@@ -0,0 +1,46 @@
+package task
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseDuration mirip time.ParseDuration tetapi juga menerima satuan hari ("d"),
+// contoh: "30m", "2h", "1d", "1d12h"
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("duration kosong")
+	}
+
+	// Komponen bertanda ditolak, contoh "1d-23h" yang jika dijumlahkan diam-diam menjadi 1h
+	if strings.ContainsAny(s, "+-") {
+		return 0, errors.New("komponen duration tidak boleh bertanda: " + s)
+	}
+
+	var total time.Duration
+	if i := strings.Index(s, "d"); i > 0 {
+		days, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, errors.New("format duration tidak valid: " + s)
+		}
+		total = time.Duration(days) * 24 * time.Hour
+		s = s[i+1:]
+	}
+
+	if s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		total += d
+	}
+
+	if total <= 0 {
+		return 0, errors.New("duration harus lebih dari nol")
+	}
+
+	return total, nil
+}
@@ -0,0 +1,31 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30m":   30 * time.Minute,
+		"2h":    2 * time.Hour,
+		"1d":    24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+	}
+
+	for input, expected := range cases {
+		d, err := parseDuration(input)
+		if assert.NoError(t, err, input) {
+			assert.Equal(t, expected, d, input)
+		}
+	}
+}
+
+func TestParseInvalidDuration(t *testing.T) {
+	for _, input := range []string{"", "d", "xd", "-1h", "0m", "soon", "1d-23h", "+1d", "1d+2h", "1h-30m"} {
+		_, err := parseDuration(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,39 @@
+package task
+
+import (
+	"log"
+
+	dto "todo_list_consumer/src/app/dto/task"
+)
+
+// notifyTransition mengambil snapshot terbaru task lalu mengirim event-nya
+func (uc *taskUseCase) notifyTransition(event string, reason string, taskID int64) {
+	task, err := uc.Repo.GetTask(taskID)
+	if err != nil {
+		log.Println("Gagal mengambil task untuk event:", err)
+		return
+	}
+
+	uc.publishTaskEvent(event, reason, task)
+}
+
+// publishTaskEvent mengirim snapshot task ke NATS
+func (uc *taskUseCase) publishTaskEvent(event string, reason string, task *dto.TaskDTO) {
+	payload := dto.TaskEventDTO{
+		Event:  event,
+		Reason: reason,
+		Task:   task,
+	}
+
+	if err := uc.publish(payload); err != nil {
+		log.Printf("Gagal mengirim event %s untuk task ID %d: %+v", event, task.ID, err)
+	}
+}
+
+// publish melengkapi daftar user yang perlu dinotifikasi lalu mengirim event ke subject sesuai nama event
+func (uc *taskUseCase) publish(payload dto.TaskEventDTO) error {
+	payload.NotifyUserIDs = uc.recipients(payload.Task)
+	payload.OccurredAt = uc.Clock.Now()
+
+	return uc.Publisher.Publish(payload.Event, payload)
+}
@@ -0,0 +1,114 @@
+package task
+
+import (
+	"log"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// ExpireTask dipanggil worker scheduler ketika deadline task terlewati dan menjalankan
+// kebijakan expiry task tersebut. Task yang sudah dihapus, tidak pending atau expiry-nya
+// dibatalkan admin diabaikan sehingga jadwal yang diproses ulang oleh scheduler tidak menghasilkan error
+func (uc *taskUseCase) ExpireTask(req *dto.ExpireTaskReqDTO) error {
+	task, err := uc.Repo.GetTask(req.ID)
+	if err == repo.ErrTaskNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if task.Status != "pending" || task.ExpiryCancelled {
+		return nil
+	}
+
+	switch expiryAction(task, uc.Conf, uc.Clock.Now()) {
+	case taskConst.EXPIRY_POLICY_NOTIFY_ONLY:
+		return uc.markOverdue(task, req.Meta)
+	case taskConst.EXPIRY_POLICY_AUTO_EXTEND:
+		return uc.autoExtend(task, req.Meta)
+	case taskConst.EXPIRY_POLICY_GRACE:
+		return uc.startGrace(task, req.Meta)
+	default:
+		return uc.expireTask(task.ID, "", req.Meta)
+	}
+}
+
+// expiryAction menentukan tindakan saat key expiry task jatuh tempo. auto_extend yang sudah mencapai
+// batas dan grace yang masa tenggangnya sudah habis berakhir dengan expire biasa
+func expiryAction(task *dto.TaskDTO, conf config.TaskConf, now time.Time) string {
+	switch task.ExpiryPolicy {
+	case taskConst.EXPIRY_POLICY_NOTIFY_ONLY:
+		return taskConst.EXPIRY_POLICY_NOTIFY_ONLY
+	case taskConst.EXPIRY_POLICY_AUTO_EXTEND:
+		if task.ExtendCount < conf.AutoExtendMax {
+			return taskConst.EXPIRY_POLICY_AUTO_EXTEND
+		}
+	case taskConst.EXPIRY_POLICY_GRACE:
+		// Toleransi satu detik untuk selisih jam antara Redis dan service
+		if now.Add(time.Second).Before(task.ExpiresAt.Add(conf.GracePeriod)) {
+			return taskConst.EXPIRY_POLICY_GRACE
+		}
+	}
+
+	return taskConst.EXPIRY_POLICY_EXPIRE
+}
+
+// markOverdue mencatat dan mengirim event overdue tanpa mengubah status task
+func (uc *taskUseCase) markOverdue(task *dto.TaskDTO, meta dto.EventMetaDTO) error {
+	err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		return record(r, task.ID, historyOverdue, 0, meta, nil, fields{"expires_at": task.ExpiresAt, "policy": task.ExpiryPolicy})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	uc.publishTaskEvent(taskConst.TASK_OVERDUE_EVENT, task.ExpiryPolicy, task)
+	return nil
+}
+
+// autoExtend memundurkan deadline sebesar AutoExtendInterval lalu menjadwalkan ulang expiry-nya
+func (uc *taskUseCase) autoExtend(task *dto.TaskDTO, meta dto.EventMetaDTO) error {
+	expiresAt := task.ExpiresAt.Add(uc.Conf.AutoExtendInterval)
+	if now := uc.Clock.Now(); expiresAt.Before(now) {
+		expiresAt = now.Add(uc.Conf.AutoExtendInterval)
+	}
+
+	err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.ExtendTask(task.ID, expiresAt); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyExtended, 0, meta,
+			fields{"expires_at": task.ExpiresAt, "extend_count": task.ExtendCount},
+			fields{"expires_at": expiresAt, "extend_count": task.ExtendCount + 1})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Deadline task ID %d diperpanjang otomatis hingga %s", task.ID, expiresAt.UTC().Format(time.RFC3339))
+	uc.reschedule(task.ID, expiresAt)
+	uc.notifyTransition(taskConst.TASK_EXTENDED_EVENT, task.ExpiryPolicy, task.ID)
+
+	return nil
+}
+
+// startGrace menjadwalkan ulang expiry ke akhir masa tenggang dan mengirim event overdue.
+// Saat key tersebut jatuh tempo, expiryAction mengembalikan expire karena masa tenggang sudah habis
+func (uc *taskUseCase) startGrace(task *dto.TaskDTO, meta dto.EventMetaDTO) error {
+	deadline := task.ExpiresAt.Add(uc.Conf.GracePeriod)
+	if err := uc.Scheduler.ExtendTaskCancellation(task.ID, deadline); err != nil {
+		return err
+	}
+
+	return uc.markOverdue(task, meta)
+}
@@ -0,0 +1,106 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiryAction(t *testing.T) {
+	conf := config.TaskConf{AutoExtendMax: 2, GracePeriod: time.Hour}
+	expiresAt := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		task   dto.TaskDTO
+		now    time.Time
+		action string
+	}{
+		{"expire", dto.TaskDTO{ExpiryPolicy: taskConst.EXPIRY_POLICY_EXPIRE}, expiresAt, taskConst.EXPIRY_POLICY_EXPIRE},
+		{"notify only", dto.TaskDTO{ExpiryPolicy: taskConst.EXPIRY_POLICY_NOTIFY_ONLY}, expiresAt, taskConst.EXPIRY_POLICY_NOTIFY_ONLY},
+		{"auto extend", dto.TaskDTO{ExpiryPolicy: taskConst.EXPIRY_POLICY_AUTO_EXTEND, ExtendCount: 1}, expiresAt, taskConst.EXPIRY_POLICY_AUTO_EXTEND},
+		{"auto extend habis", dto.TaskDTO{ExpiryPolicy: taskConst.EXPIRY_POLICY_AUTO_EXTEND, ExtendCount: 2}, expiresAt, taskConst.EXPIRY_POLICY_EXPIRE},
+		{"grace mulai", dto.TaskDTO{ExpiryPolicy: taskConst.EXPIRY_POLICY_GRACE, ExpiresAt: expiresAt}, expiresAt, taskConst.EXPIRY_POLICY_GRACE},
+		{"grace habis", dto.TaskDTO{ExpiryPolicy: taskConst.EXPIRY_POLICY_GRACE, ExpiresAt: expiresAt}, expiresAt.Add(time.Hour), taskConst.EXPIRY_POLICY_EXPIRE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.action, expiryAction(&tt.task, conf, tt.now))
+		})
+	}
+}
+
+func TestExpireTaskPolicies(t *testing.T) {
+	expiresAt := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	conf := config.TaskConf{AutoExtendMax: 1, AutoExtendInterval: 24 * time.Hour, GracePeriod: time.Hour}
+	meta := dto.EventMetaDTO{Source: taskConst.SOURCE_SCHEDULER, MessageID: "task:1:expire"}
+
+	t.Run("notify only menandai overdue tanpa mengubah status", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: expiresAt, ExpiryPolicy: taskConst.EXPIRY_POLICY_NOTIFY_ONLY})
+		uc, _, p, _ := newTestUseCase(r, conf)
+
+		assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+		assert.Equal(t, "pending", r.tasks[1].Status)
+		assert.Equal(t, []string{historyOverdue}, r.events(1))
+		assert.Equal(t, []string{taskConst.TASK_OVERDUE_EVENT}, p.subjects)
+	})
+
+	t.Run("auto extend memundurkan deadline sampai batas", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: expiresAt, ExpiryPolicy: taskConst.EXPIRY_POLICY_AUTO_EXTEND})
+		uc, s, p, c := newTestUseCase(r, conf)
+
+		assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+		assert.Equal(t, "pending", r.tasks[1].Status)
+		assert.Equal(t, expiresAt.Add(24*time.Hour), r.tasks[1].ExpiresAt)
+		assert.Equal(t, expiresAt.Add(24*time.Hour), s.expiries[1])
+		assert.Equal(t, []string{historyExtended}, r.events(1))
+		assert.Equal(t, []string{taskConst.TASK_EXTENDED_EVENT}, p.subjects)
+
+		// Batas AutoExtendMax tercapai sehingga deadline berikutnya berakhir expired
+		c.Advance(24 * time.Hour)
+		assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+		assert.Equal(t, "expired", r.tasks[1].Status)
+		assert.Equal(t, []string{historyExtended, historyExpired}, r.events(1))
+	})
+
+	t.Run("auto extend dihitung dari sekarang jika deadline sudah lama lewat", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: expiresAt.Add(-48 * time.Hour), ExpiryPolicy: taskConst.EXPIRY_POLICY_AUTO_EXTEND})
+		uc, _, _, c := newTestUseCase(r, conf)
+
+		assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+		assert.Equal(t, c.Now().Add(24*time.Hour), r.tasks[1].ExpiresAt)
+	})
+
+	t.Run("auto extend mengikuti clock yang sudah maju", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: expiresAt, ExpiryPolicy: taskConst.EXPIRY_POLICY_AUTO_EXTEND})
+		uc, s, _, c := newTestUseCase(r, conf)
+
+		// Worker baru memproses jadwal tiga hari setelah deadline
+		c.Advance(72 * time.Hour)
+		assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+		assert.Equal(t, c.Now().Add(24*time.Hour), r.tasks[1].ExpiresAt)
+		assert.Equal(t, c.Now().Add(24*time.Hour), s.expiries[1])
+	})
+
+	t.Run("grace menunda expire sampai masa tenggang habis", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: expiresAt, ExpiryPolicy: taskConst.EXPIRY_POLICY_GRACE})
+		uc, s, p, c := newTestUseCase(r, conf)
+
+		assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+		assert.Equal(t, "pending", r.tasks[1].Status)
+		assert.Equal(t, expiresAt.Add(time.Hour), s.expiries[1])
+		assert.Equal(t, []string{historyOverdue}, r.events(1))
+		assert.Equal(t, []string{taskConst.TASK_OVERDUE_EVENT}, p.subjects)
+
+		c.Advance(time.Hour)
+		assert.NoError(t, uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: 1, Meta: meta}))
+		assert.Equal(t, "expired", r.tasks[1].Status)
+		assert.Equal(t, []string{historyOverdue, historyExpired}, r.events(1))
+	})
+}
@@ -0,0 +1,850 @@
+package task
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/clock"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// errNoRows meniru error repository ketika UPDATE tidak mengubah satu baris pun
+var errNoRows = errors.New("no rows affected")
+
+var _ repo.TaskRepository = (*fakeRepo)(nil)
+
+// fakeSession meniru satu baris public.task_sessions
+type fakeSession struct {
+	taskID    int64
+	userID    int64
+	startedAt time.Time
+	endedAt   *time.Time
+}
+
+// fakeState adalah isi tabel yang disimpan fakeRepo, disalin utuh setiap kali transaksi dimulai
+type fakeState struct {
+	tasks     map[int64]*dto.TaskDTO
+	created   map[int64]time.Time // tasks.created_at
+	blockers  map[int64][]int64   // task_id -> blocked_by_id
+	members   map[int64][]dto.CollaboratorDTO
+	series    map[int64]*dto.TaskSeriesDTO
+	archived  map[int64]*dto.TaskDTO
+	quotas    map[int64]dto.QuotaUsageDTO // hanya MaxPending dan MaxCreated yang dipakai
+	timezones map[int64]string
+	sessions  []fakeSession
+	history   []dto.TaskHistoryDTO
+}
+
+func (s fakeState) clone() fakeState {
+	c := fakeState{
+		tasks:     map[int64]*dto.TaskDTO{},
+		created:   map[int64]time.Time{},
+		blockers:  map[int64][]int64{},
+		members:   map[int64][]dto.CollaboratorDTO{},
+		series:    map[int64]*dto.TaskSeriesDTO{},
+		archived:  map[int64]*dto.TaskDTO{},
+		quotas:    map[int64]dto.QuotaUsageDTO{},
+		timezones: map[int64]string{},
+		sessions:  append([]fakeSession(nil), s.sessions...),
+		history:   append([]dto.TaskHistoryDTO(nil), s.history...),
+	}
+
+	for id, task := range s.tasks {
+		copied := *task
+		copied.Tags = append([]string(nil), task.Tags...)
+		c.tasks[id] = &copied
+	}
+	for id, task := range s.archived {
+		copied := *task
+		c.archived[id] = &copied
+	}
+	for id, series := range s.series {
+		copied := *series
+		c.series[id] = &copied
+	}
+	for id, ids := range s.blockers {
+		c.blockers[id] = append([]int64(nil), ids...)
+	}
+	for id, members := range s.members {
+		c.members[id] = append([]dto.CollaboratorDTO(nil), members...)
+	}
+	for id, at := range s.created {
+		c.created[id] = at
+	}
+	for id, quota := range s.quotas {
+		c.quotas[id] = quota
+	}
+	for id, tz := range s.timezones {
+		c.timezones[id] = tz
+	}
+
+	return c
+}
+
+// fakeRepo adalah TaskRepository in-memory untuk test use case. Setiap metode meniru query di
+// repositories/task, termasuk kondisi WHERE dan error yang dikembalikan ketika tidak ada baris yang berubah
+type fakeRepo struct {
+	fakeState
+	clock clock.Clock // diganti newTestUseCase dengan clock yang sama dengan use case
+	inTx  bool
+
+	errAddTags error // dikembalikan AddTags untuk mensimulasikan kegagalan di tengah transaksi
+	lockHeld   bool  // lock reconciler sedang dipegang replica lain
+}
+
+func newFakeRepo(tasks ...dto.TaskDTO) *fakeRepo {
+	f := &fakeRepo{fakeState: fakeState{}.clone(), clock: clock.NewFake(time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC))}
+	for i := range tasks {
+		task := tasks[i]
+		if task.Status == "" {
+			task.Status = "pending"
+		}
+		f.tasks[task.ID] = &task
+	}
+
+	return f
+}
+
+// WithTransaction menyimpan salinan state lalu mengembalikannya jika fn gagal. Pemanggilan
+// bertingkat memakai transaksi yang sudah berjalan
+func (f *fakeRepo) WithTransaction(fn func(r repo.TaskRepository) error) error {
+	if f.inTx {
+		return fn(f)
+	}
+
+	saved := f.fakeState.clone()
+
+	f.inTx = true
+	err := fn(f)
+	f.inTx = false
+
+	if err != nil {
+		f.fakeState = saved
+	}
+
+	return err
+}
+
+func (f *fakeRepo) WithReconcileLock(fn func() error) (bool, error) {
+	if f.lockHeld {
+		return false, nil
+	}
+
+	return true, fn()
+}
+
+func (f *fakeRepo) AddTask(req *dto.CreateTaskReqDTO) (*dto.CreateTaskRespDTO, error) {
+	f.mustTx("AddTask")
+
+	id := f.nextID()
+	f.tasks[id] = &dto.TaskDTO{
+		ID:           id,
+		UserID:       req.UserID,
+		Title:        req.Title,
+		Description:  req.Description,
+		Priority:     req.Priority,
+		Status:       "pending",
+		ExpiresAt:    req.ExpiresAt.Time,
+		ParentID:     req.ParentID,
+		ExpiryPolicy: req.ExpiryPolicy,
+	}
+	f.created[id] = f.clock.Now()
+
+	return &dto.CreateTaskRespDTO{ID: id}, nil
+}
+
+// FinishTask meniru query FinishTask yang hanya mengubah task pending
+func (f *fakeRepo) FinishTask(req *dto.FinishtTaskReqDTO) error {
+	f.mustTx("FinishTask")
+	if !f.setStatus(req.ID, "done") {
+		return repo.ErrTaskNotPending
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) ExpireTask(req *dto.ExpireTaskReqDTO) error {
+	if !f.setStatus(req.ID, "expired") {
+		return errNoRows
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) GetTask(id int64) (*dto.TaskDTO, error) {
+	task, ok := f.tasks[id]
+	if !ok {
+		return nil, repo.ErrTaskNotFound
+	}
+
+	return f.read(task), nil
+}
+
+func (f *fakeRepo) SnoozeTask(id int64, expiresAt time.Time, maxSnooze int) error {
+	f.mustTx("SnoozeTask")
+	task := f.pending(id)
+	if task == nil || (maxSnooze > 0 && task.SnoozeCount >= maxSnooze) {
+		return errNoRows
+	}
+
+	task.ExpiresAt = expiresAt
+	task.SnoozeCount++
+	task.ExpiryCancelled = false
+	return nil
+}
+
+func (f *fakeRepo) ExtendTask(id int64, expiresAt time.Time) error {
+	f.mustTx("ExtendTask")
+	task := f.pending(id)
+	if task == nil {
+		return errNoRows
+	}
+
+	task.ExpiresAt = expiresAt
+	task.ExtendCount++
+	return nil
+}
+
+func (f *fakeRepo) RescheduleTask(id int64, expiresAt time.Time) error {
+	f.mustTx("RescheduleTask")
+	task := f.pending(id)
+	if task == nil {
+		return errNoRows
+	}
+
+	task.ExpiresAt = expiresAt
+	task.ExpiryCancelled = false
+	return nil
+}
+
+func (f *fakeRepo) CancelExpiry(id int64) error {
+	f.mustTx("CancelExpiry")
+	task := f.pending(id)
+	if task == nil {
+		return errNoRows
+	}
+
+	task.ExpiryCancelled = true
+	return nil
+}
+
+func (f *fakeRepo) AddSeries(series *dto.TaskSeriesDTO) (int64, error) {
+	f.mustTx("AddSeries")
+	id := int64(len(f.series) + 1)
+	copied := *series
+	copied.ID = id
+	copied.Active = true
+	f.series[id] = &copied
+	return id, nil
+}
+
+func (f *fakeRepo) GetSeries(id int64) (*dto.TaskSeriesDTO, error) {
+	series, ok := f.series[id]
+	if !ok {
+		return nil, repo.ErrSeriesNotFound
+	}
+
+	copied := *series
+	return &copied, nil
+}
+
+// UpdateSeries hanya mengubah kolom yang diubah query UpdateSeries
+func (f *fakeRepo) UpdateSeries(series *dto.TaskSeriesDTO) error {
+	f.mustTx("UpdateSeries")
+	current, ok := f.series[series.ID]
+	if !ok || !current.Active {
+		return repo.ErrSeriesNotFound
+	}
+
+	current.Title, current.RRule, current.Timezone, current.DTStart = series.Title, series.RRule, series.Timezone, series.DTStart
+	return nil
+}
+
+func (f *fakeRepo) StopSeries(id int64) error {
+	f.mustTx("StopSeries")
+	series, ok := f.series[id]
+	if !ok || !series.Active {
+		return repo.ErrSeriesNotFound
+	}
+
+	series.Active = false
+	return nil
+}
+
+func (f *fakeRepo) GetLatestOccurrence(seriesID int64) (int64, error) {
+	var latest *dto.TaskDTO
+	for _, id := range f.ids() {
+		task := f.tasks[id]
+		if task.SeriesID != nil && *task.SeriesID == seriesID && (latest == nil || !task.ExpiresAt.Before(latest.ExpiresAt)) {
+			latest = task
+		}
+	}
+
+	if latest == nil {
+		return 0, repo.ErrTaskNotFound
+	}
+
+	return latest.ID, nil
+}
+
+// AddOccurrence meniru unique index (series_id, expires_at) yang mencegah occurrence ganda
+func (f *fakeRepo) AddOccurrence(series *dto.TaskSeriesDTO, expiresAt time.Time) (*dto.CreateTaskRespDTO, error) {
+	f.mustTx("AddOccurrence")
+	for _, task := range f.tasks {
+		if task.SeriesID != nil && *task.SeriesID == series.ID && task.ExpiresAt.Equal(expiresAt) {
+			return nil, repo.ErrOccurrenceExists
+		}
+	}
+
+	id := f.nextID()
+	f.tasks[id] = &dto.TaskDTO{
+		ID:           id,
+		UserID:       series.UserID,
+		Title:        series.Title,
+		Description:  series.Description,
+		Priority:     series.Priority,
+		Status:       "pending",
+		ExpiresAt:    expiresAt,
+		SeriesID:     int64Ptr(series.ID),
+		ExpiryPolicy: series.ExpiryPolicy,
+	}
+	f.created[id] = f.clock.Now()
+
+	return &dto.CreateTaskRespDTO{ID: id}, nil
+}
+
+func (f *fakeRepo) GetChildren(parentID int64) ([]dto.TaskDTO, error) {
+	children := []dto.TaskDTO{}
+	for _, id := range f.ids() {
+		if task := f.tasks[id]; task.ParentID != nil && *task.ParentID == parentID {
+			children = append(children, *f.read(task))
+		}
+	}
+
+	return children, nil
+}
+
+// DeleteTask meniru foreign key: member, tag dan dependency ikut terhapus, subtask dilepas dari parent
+func (f *fakeRepo) DeleteTask(id int64) error {
+	if _, ok := f.tasks[id]; !ok {
+		return repo.ErrTaskNotFound
+	}
+
+	f.drop(id)
+	return nil
+}
+
+func (f *fakeRepo) DetachTask(id int64) error {
+	if task, ok := f.tasks[id]; ok {
+		task.ParentID = nil
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) AddTags(taskID int64, tags []string) error {
+	f.mustTx("AddTags")
+	if f.errAddTags != nil || len(tags) == 0 {
+		return f.errAddTags
+	}
+
+	if task, ok := f.tasks[taskID]; ok {
+		task.Tags = sortedUnion(task.Tags, tags)
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) RemoveTags(taskID int64, tags []string) error {
+	task, ok := f.tasks[taskID]
+	if !ok {
+		return nil
+	}
+
+	kept := []string{}
+	for _, tag := range task.Tags {
+		if !containsString(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	task.Tags = kept
+
+	return nil
+}
+
+func (f *fakeRepo) ChangePriority(taskID int64, priority string) error {
+	task, ok := f.tasks[taskID]
+	if !ok {
+		return repo.ErrTaskNotFound
+	}
+
+	task.Priority = priority
+	return nil
+}
+
+func (f *fakeRepo) AssignTask(taskID int64, assigneeID int64) error {
+	f.mustTx("AssignTask")
+	task, ok := f.tasks[taskID]
+	if !ok {
+		return repo.ErrTaskNotFound
+	}
+
+	task.AssigneeID = &assigneeID
+	return nil
+}
+
+// AddMembers meniru ON CONFLICT (task_id, user_id) yang memperbarui permission kolaborator lama
+func (f *fakeRepo) AddMembers(taskID int64, members []dto.CollaboratorDTO) error {
+	f.mustTx("AddMembers")
+	for _, member := range members {
+		f.RemoveMembers(taskID, []int64{member.UserID})
+		f.members[taskID] = append(f.members[taskID], member)
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) RemoveMembers(taskID int64, userIDs []int64) error {
+	kept := []dto.CollaboratorDTO{}
+	for _, member := range f.members[taskID] {
+		if !containsID(userIDs, member.UserID) {
+			kept = append(kept, member)
+		}
+	}
+	f.members[taskID] = kept
+
+	return nil
+}
+
+func (f *fakeRepo) GetMembers(taskID int64) ([]dto.CollaboratorDTO, error) {
+	members := append([]dto.CollaboratorDTO{}, f.members[taskID]...)
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+
+	return members, nil
+}
+
+// AddDependency meniru pengecekan siklus di repository dan ON CONFLICT DO NOTHING
+func (f *fakeRepo) AddDependency(taskID int64, blockedByID int64) error {
+	f.mustTx("AddDependency")
+	if f.reachable(blockedByID, taskID) {
+		return repo.ErrDependencyCycle
+	}
+
+	if !containsID(f.blockers[taskID], blockedByID) {
+		f.blockers[taskID] = append(f.blockers[taskID], blockedByID)
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) RemoveDependency(taskID int64, blockedByID int64) error {
+	kept := []int64{}
+	for _, id := range f.blockers[taskID] {
+		if id != blockedByID {
+			kept = append(kept, id)
+		}
+	}
+	f.blockers[taskID] = kept
+
+	return nil
+}
+
+func (f *fakeRepo) GetUnfinishedBlockers(taskID int64) ([]int64, error) {
+	ids := []int64{}
+	for _, id := range f.ids() {
+		if containsID(f.blockers[taskID], id) && f.tasks[id].Status == "pending" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (f *fakeRepo) GetPendingDependents(blockerID int64) ([]int64, error) {
+	ids := []int64{}
+	for _, id := range f.ids() {
+		if containsID(f.blockers[id], blockerID) && f.tasks[id].Status == "pending" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (f *fakeRepo) FlagTask(taskID int64, reason string) error {
+	if task, ok := f.tasks[taskID]; ok {
+		task.FlagReason = &reason
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) AddHistory(history *dto.TaskHistoryDTO) error {
+	f.mustTx("AddHistory")
+	f.appendHistory(*history)
+	return nil
+}
+
+func (f *fakeRepo) GetHistory(taskID int64) ([]dto.TaskHistoryDTO, error) {
+	history := []dto.TaskHistoryDTO{}
+	for _, h := range f.history {
+		if h.TaskID == taskID {
+			history = append(history, h)
+		}
+	}
+
+	return history, nil
+}
+
+func (f *fakeRepo) GetQuotaUsage(userID int64, since time.Time) (*dto.QuotaUsageDTO, error) {
+	usage := f.quotas[userID]
+	usage.Pending, usage.Created = 0, 0
+	for id, task := range f.tasks {
+		if task.UserID != userID {
+			continue
+		}
+		if task.Status == "pending" {
+			usage.Pending++
+		}
+		if !f.created[id].Before(since) {
+			usage.Created++
+		}
+	}
+
+	return &usage, nil
+}
+
+// ArchiveTasks meniru CTE ArchiveTasks: task done/expired sebelum before yang tidak punya subtask atau
+// dependent pending disalin ke arsip (menimpa arsip lama) lalu dihapus. Event archived menyimpan
+// kolaborator yang ikut terhapus dan subtask di luar batch yang parent_id-nya dilepas mendapat event detached
+func (f *fakeRepo) ArchiveTasks(before time.Time, batchSize int, source string) (int64, error) {
+	batch := []int64{}
+	for _, id := range f.ids() {
+		task := f.tasks[id]
+		if len(batch) < batchSize && (task.Status == "done" || task.Status == "expired") &&
+			task.ExpiresAt.Before(before) && !f.hasPendingRelations(id) {
+			batch = append(batch, id)
+		}
+	}
+
+	// Semua sub-statement CTE membaca snapshot sebelum DELETE
+	history := []dto.TaskHistoryDTO{}
+	for _, id := range batch {
+		members, _ := f.GetMembers(id)
+		newValue, _ := marshalFields(fields{"members": members})
+		history = append(history, dto.TaskHistoryDTO{TaskID: id, Event: historyArchived, Source: source, OldValue: []byte("{}"), NewValue: newValue})
+	}
+	for _, id := range batch {
+		for _, child := range f.ids() {
+			if parentID := f.tasks[child].ParentID; parentID != nil && *parentID == id && !containsID(batch, child) {
+				oldValue, _ := marshalFields(fields{"parent_id": id})
+				newValue, _ := marshalFields(fields{"parent_id": nil, "reason": reasonParentArchived})
+				history = append(history, dto.TaskHistoryDTO{TaskID: child, Event: historyDetached, Source: source, OldValue: oldValue, NewValue: newValue})
+			}
+		}
+	}
+
+	for _, id := range batch {
+		copied := *f.tasks[id]
+		copied.TrackedSeconds = 0
+		f.archived[id] = &copied
+		f.drop(id)
+	}
+	for _, h := range history {
+		f.appendHistory(h)
+	}
+
+	return int64(len(batch)), nil
+}
+
+func (f *fakeRepo) GetArchivedTask(id int64) (*dto.TaskDTO, error) {
+	task, ok := f.archived[id]
+	if !ok {
+		return nil, repo.ErrTaskNotFound
+	}
+
+	copied := *task
+	return &copied, nil
+}
+
+func (f *fakeRepo) GetUserTimezone(userID int64) (string, error) {
+	return f.timezones[userID], nil
+}
+
+func (f *fakeRepo) SetUserTimezone(userID int64, timezone string) error {
+	f.timezones[userID] = timezone
+	return nil
+}
+
+// StartSession meniru unique index sesi terbuka per (task_id, user_id)
+func (f *fakeRepo) StartSession(taskID int64, userID int64, at time.Time) error {
+	f.mustTx("StartSession")
+	if f.openSession(taskID, userID) != nil {
+		return repo.ErrSessionOpen
+	}
+
+	f.sessions = append(f.sessions, fakeSession{taskID: taskID, userID: userID, startedAt: at})
+	return nil
+}
+
+func (f *fakeRepo) StopSession(taskID int64, userID int64, at time.Time) error {
+	f.mustTx("StopSession")
+	s := f.openSession(taskID, userID)
+	if s == nil {
+		return repo.ErrSessionNotOpen
+	}
+
+	s.close(at)
+	return nil
+}
+
+func (f *fakeRepo) CloseSessions(taskID int64, at time.Time) error {
+	for i := range f.sessions {
+		if f.sessions[i].taskID == taskID && f.sessions[i].endedAt == nil {
+			f.sessions[i].close(at)
+		}
+	}
+
+	return nil
+}
+
+// GetTimeReport menjumlahkan sesi per hari mulai di timezone, sesi terbuka dihitung sampai clock repository
+func (f *fakeRepo) GetTimeReport(userID int64, timezone string, from time.Time, to time.Time) ([]dto.TimeReportDTO, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := map[string]int64{}
+	for _, s := range f.sessions {
+		if s.userID == userID && !s.startedAt.Before(from) && s.startedAt.Before(to) {
+			seconds[s.startedAt.In(loc).Format("2006-01-02")] += s.seconds(f.clock.Now())
+		}
+	}
+
+	report := []dto.TimeReportDTO{}
+	for day, total := range seconds {
+		report = append(report, dto.TimeReportDTO{Day: day, Seconds: total})
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Day < report[j].Day })
+
+	return report, nil
+}
+
+// GetPendingTasks meniru keyset pagination dan kolom overdue query GetPendingTasks
+func (f *fakeRepo) GetPendingTasks(afterID int64, limit int) ([]dto.PendingTaskDTO, error) {
+	tasks := []dto.PendingTaskDTO{}
+	for _, id := range f.ids() {
+		task := f.tasks[id]
+		if id > afterID && task.Status == "pending" && !task.ExpiryCancelled && len(tasks) < limit {
+			tasks = append(tasks, dto.PendingTaskDTO{ID: id, ExpiresAt: task.ExpiresAt, ExpiryPolicy: task.ExpiryPolicy,
+				Overdue: f.overdue(task)})
+		}
+	}
+
+	return tasks, nil
+}
+
+// events mengembalikan nama event riwayat milik taskID sesuai urutan penulisan
+func (f *fakeRepo) events(taskID int64) []string {
+	events := []string{}
+	for _, h := range f.history {
+		if h.TaskID == taskID {
+			events = append(events, h.Event)
+		}
+	}
+
+	return events
+}
+
+// setStatus mengubah status task yang masih pending, false jika tidak ada baris yang berubah
+func (f *fakeRepo) setStatus(id int64, status string) bool {
+	task := f.pending(id)
+	if task == nil {
+		return false
+	}
+
+	task.Status = status
+	return true
+}
+
+// pending mengembalikan task jika masih pending, meniru kondisi WHERE status = 'pending'
+func (f *fakeRepo) pending(id int64) *dto.TaskDTO {
+	if task, ok := f.tasks[id]; ok && task.Status == "pending" {
+		return task
+	}
+
+	return nil
+}
+
+// read mengembalikan salinan task beserta tracked_seconds seperti kolom taskColumns
+func (f *fakeRepo) read(task *dto.TaskDTO) *dto.TaskDTO {
+	copied := *task
+	copied.Tags = sortedUnion(nil, task.Tags)
+	for _, s := range f.sessions {
+		if s.taskID == task.ID {
+			copied.TrackedSeconds += s.seconds(f.clock.Now())
+		}
+	}
+
+	return &copied
+}
+
+func (f *fakeRepo) drop(id int64) {
+	delete(f.tasks, id)
+	delete(f.members, id)
+	delete(f.blockers, id)
+	for taskID := range f.blockers {
+		f.RemoveDependency(taskID, id)
+	}
+	for _, task := range f.tasks {
+		if task.ParentID != nil && *task.ParentID == id {
+			task.ParentID = nil
+		}
+	}
+}
+
+func (f *fakeRepo) hasPendingRelations(id int64) bool {
+	dependents, _ := f.GetPendingDependents(id)
+	if len(dependents) > 0 {
+		return true
+	}
+
+	for _, task := range f.tasks {
+		if task.ParentID != nil && *task.ParentID == id && task.Status == "pending" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reachable menelusuri rantai blocked_by dari from, true jika to bisa dicapai
+func (f *fakeRepo) reachable(from int64, to int64) bool {
+	visited := map[int64]bool{}
+	frontier := []int64{from}
+	for len(frontier) > 0 {
+		id := frontier[0]
+		frontier = frontier[1:]
+		if id == to {
+			return true
+		}
+
+		if !visited[id] {
+			visited[id] = true
+			frontier = append(frontier, f.blockers[id]...)
+		}
+	}
+
+	return false
+}
+
+func (f *fakeRepo) overdue(task *dto.TaskDTO) bool {
+	for _, h := range f.history {
+		var value struct {
+			ExpiresAt time.Time `json:"expires_at"`
+		}
+		if h.TaskID == task.ID && h.Event == historyOverdue && h.NewValue.Unmarshal(&value) == nil && value.ExpiresAt.Equal(task.ExpiresAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *fakeRepo) openSession(taskID int64, userID int64) *fakeSession {
+	for i := range f.sessions {
+		if s := &f.sessions[i]; s.taskID == taskID && s.userID == userID && s.endedAt == nil {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeRepo) appendHistory(h dto.TaskHistoryDTO) {
+	h.ID = int64(len(f.history) + 1)
+	h.CreatedAt = f.clock.Now()
+	f.history = append(f.history, h)
+}
+
+func (f *fakeRepo) nextID() int64 {
+	id := int64(1)
+	if ids := f.ids(); len(ids) > 0 {
+		id = ids[len(ids)-1] + 1
+	}
+	for f.archived[id] != nil {
+		id++
+	}
+
+	return id
+}
+
+// mustTx memastikan operasi tulis dipanggil lewat repository transaksi
+func (f *fakeRepo) mustTx(op string) {
+	if !f.inTx {
+		panic(op + " dipanggil di luar transaksi")
+	}
+}
+
+func (f *fakeRepo) ids() []int64 {
+	ids := make([]int64, 0, len(f.tasks))
+	for id := range f.tasks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// close meniru ended_at = GREATEST(at, started_at)
+func (s *fakeSession) close(at time.Time) {
+	if at.Before(s.startedAt) {
+		at = s.startedAt
+	}
+	s.endedAt = &at
+}
+
+func (s fakeSession) seconds(now time.Time) int64 {
+	end := now
+	if s.endedAt != nil {
+		end = *s.endedAt
+	}
+
+	return int64(end.Sub(s.startedAt).Seconds())
+}
+
+func sortedUnion(a []string, b []string) []string {
+	tags := append([]string{}, a...)
+	for _, tag := range b {
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, value := range ids {
+		if value == id {
+			return true
+		}
+	}
+
+	return false
+}
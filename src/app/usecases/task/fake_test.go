@@ -0,0 +1,164 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+)
+
+var _ infra_scheduler.SchedulerInterface = (*fakeScheduler)(nil)
+
+// fakeScheduler mencatat jadwal expire dan reminder yang dibuat use case
+type fakeScheduler struct {
+	mu        sync.Mutex
+	expiries  map[int64]time.Time
+	reminders map[int64]time.Time
+}
+
+func newFakeScheduler() *fakeScheduler {
+	return &fakeScheduler{expiries: map[int64]time.Time{}, reminders: map[int64]time.Time{}}
+}
+
+func (s *fakeScheduler) ScheduleTaskCancellation(taskID int64, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expiries[taskID] = expiresAt
+	return nil
+}
+
+func (s *fakeScheduler) ExtendTaskCancellation(taskID int64, expiresAt time.Time) error {
+	return s.ScheduleTaskCancellation(taskID, expiresAt)
+}
+
+func (s *fakeScheduler) CancelTaskCancellation(taskID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expiries, taskID)
+	return nil
+}
+
+func (s *fakeScheduler) ScheduleTaskReminders(taskID int64, expiresAt time.Time, offsets []time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reminders[taskID] = expiresAt
+	return nil
+}
+
+func (s *fakeScheduler) CancelTaskReminders(taskID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.reminders, taskID)
+	return nil
+}
+
+func (s *fakeScheduler) ScheduledTaskIDs() ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []int64{}
+	for id := range s.expiries {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *fakeScheduler) ReminderTaskIDs() ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []int64{}
+	for id := range s.reminders {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *fakeScheduler) ScheduledExpiry(taskID int64) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.expiries[taskID]
+	return at, ok, nil
+}
+
+func (s *fakeScheduler) UpcomingExpirations(from time.Time, to time.Time, limit int) ([]infra_scheduler.ScheduledExpiry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expirations := []infra_scheduler.ScheduledExpiry{}
+	for id, at := range s.expiries {
+		if !at.Before(from) && !at.After(to) {
+			expirations = append(expirations, infra_scheduler.ScheduledExpiry{TaskID: id, ExpiresAt: at})
+		}
+	}
+	return infra_scheduler.SortExpirations(expirations, limit), nil
+}
+
+// Use case tidak menjalankan worker, metode berikut hanya melengkapi interface
+func (s *fakeScheduler) RegisterHandler(h infra_scheduler.TaskHandler) {}
+
+func (s *fakeScheduler) StartWorker(ctx context.Context) {}
+
+func (s *fakeScheduler) Status() infra_scheduler.WorkerStatus {
+	return infra_scheduler.WorkerStopped
+}
+
+// fakePublisher mencatat subject event yang dikirim
+type fakePublisher struct {
+	subjects []string
+	payloads []dto.TaskEventDTO
+}
+
+func (p *fakePublisher) Publish(subject string, payload interface{}) error {
+	p.subjects = append(p.subjects, subject)
+	if event, ok := payload.(dto.TaskEventDTO); ok {
+		p.payloads = append(p.payloads, event)
+	}
+	return nil
+}
+
+// newTestUseCase merangkai taskUseCase dengan repository, scheduler, publisher dan clock palsu
+func newTestUseCase(r *fakeRepo, conf config.TaskConf) (*taskUseCase, *fakeScheduler, *fakePublisher, *clock.Fake) {
+	s := newFakeScheduler()
+	p := &fakePublisher{}
+	c := clock.NewFake(time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC))
+
+	if conf.ChildPolicy == "" {
+		conf.ChildPolicy = taskConst.CHILD_POLICY_CASCADE
+	}
+
+	r.clock = c
+
+	uc := &taskUseCase{Repo: r, Scheduler: s, Publisher: p, Clock: c, Conf: conf}
+	return uc, s, p, c
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+func timePtr(v time.Time) *time.Time {
+	return &v
+}
+
+// errorCode mengembalikan kode CommonError dari err, 0 jika err bukan CommonError
+func errorCode(err error) infra_errors.ErrorCode {
+	var cerr *infra_errors.CommonError
+	if errors.As(err, &cerr) {
+		return cerr.ErrorCode
+	}
+
+	return 0
+}
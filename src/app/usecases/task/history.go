@@ -0,0 +1,141 @@
+package task
+
+import (
+	"encoding/json"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// Nama event yang dicatat di riwayat task (public.task_events)
+const (
+	historyCreated           = "created"
+	historyFinished          = "finished"
+	historyExpired           = "expired"
+	historySnoozed           = "snoozed"
+	historyDeleted           = "deleted"
+	historyDetached          = "detached"
+	historyFlagged           = "flagged"
+	historyTagsAdded         = "tags_added"
+	historyTagsRemoved       = "tags_removed"
+	historyPriorityChanged   = "priority_changed"
+	historyAssigned          = "assigned"
+	historyDependencyAdded   = "dependency_added"
+	historyDependencyRemoved = "dependency_removed"
+	historyTimerStarted      = "timer_started"
+	historyTimerStopped      = "timer_stopped"
+	historyOverdue           = "overdue"
+	historyExtended          = "extended"
+	historyRescheduled       = "rescheduled"
+	historyExpiryCancelled   = "expiry_cancelled"
+	historyReminded          = "reminded"
+	historySeriesUpdated     = "series_updated"
+	historySeriesStopped     = "series_stopped"
+	historyArchived          = "archived" // ditulis langsung oleh query ArchiveTasks
+)
+
+// fields adalah nilai lama/baru yang disimpan sebagai JSON di riwayat task
+type fields map[string]interface{}
+
+// recordSeries mencatat perubahan task berulang pada riwayat occurrence terakhirnya.
+// Series yang belum punya occurrence tidak dicatat
+func recordSeries(r repo.TaskRepository, seriesID int64, event string, actorID int64, meta dto.EventMetaDTO, oldValue, newValue fields) error {
+	taskID, err := r.GetLatestOccurrence(seriesID)
+	if err == repo.ErrTaskNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	newValue["series_id"] = seriesID
+	return record(r, taskID, event, actorID, meta, oldValue, newValue)
+}
+
+// record menulis satu baris riwayat memakai r, yaitu repository yang terikat ke transaksi
+// perubahan task-nya sehingga riwayat dan perubahan ikut commit atau rollback bersama.
+// actorID 0 berarti perubahan otomatis (scheduler atau transisi turunan)
+func record(r repo.TaskRepository, taskID int64, event string, actorID int64, meta dto.EventMetaDTO, oldValue, newValue fields) error {
+	history := &dto.TaskHistoryDTO{
+		TaskID: taskID,
+		Event:  event,
+		Source: meta.Source,
+	}
+
+	if actorID != 0 {
+		history.ActorID = &actorID
+	}
+
+	if meta.MessageID != "" {
+		history.MessageID = &meta.MessageID
+	}
+
+	var err error
+	if history.OldValue, err = marshalFields(oldValue); err != nil {
+		return err
+	}
+
+	if history.NewValue, err = marshalFields(newValue); err != nil {
+		return err
+	}
+
+	return r.AddHistory(history)
+}
+
+func marshalFields(value fields) ([]byte, error) {
+	if value == nil {
+		value = fields{}
+	}
+
+	return json.Marshal(value)
+}
+
+// GetTaskTimeline mengembalikan riwayat perubahan task untuk user yang boleh melihat task tersebut.
+// Riwayat tetap tersedia untuk task yang sudah diarsipkan atau dihapus
+func (uc *taskUseCase) GetTaskTimeline(req *dto.TimelineReqDTO) ([]dto.TaskHistoryDTO, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	history, err := uc.Repo.GetHistory(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.authorizeTimeline(req.ID, req.UserID, history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// authorizeTimeline mencari task di tabel aktif lalu di arsip. Untuk task yang sudah dihapus
+// pemiliknya dibaca dari riwayat pembuatan task
+func (uc *taskUseCase) authorizeTimeline(taskID int64, userID int64, history []dto.TaskHistoryDTO) error {
+	task, err := uc.Repo.GetTask(taskID)
+	if err == repo.ErrTaskNotFound {
+		task, err = uc.Repo.GetArchivedTask(taskID)
+	}
+
+	if err == nil {
+		return uc.authorizeView(task, userID)
+	}
+
+	if err != repo.ErrTaskNotFound {
+		return err
+	}
+
+	for _, h := range history {
+		if h.Event == historyCreated && h.ActorID != nil {
+			if *h.ActorID != userID {
+				return forbidden()
+			}
+			return nil
+		}
+	}
+
+	return infra_errors.NewError(infra_errors.TASK_NOT_FOUND, err)
+}
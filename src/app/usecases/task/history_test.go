@@ -0,0 +1,150 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTaskTimelineAuthorization(t *testing.T) {
+	r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, AssigneeID: int64Ptr(8)})
+	r.members[1] = []dto.CollaboratorDTO{{UserID: 9, Permission: taskConst.PERMISSION_VIEW}}
+	r.archived[2] = &dto.TaskDTO{ID: 2, UserID: 7}
+	r.history = []dto.TaskHistoryDTO{
+		{TaskID: 1, Event: historyCreated, ActorID: int64Ptr(7)},
+		{TaskID: 3, Event: historyCreated, ActorID: int64Ptr(7)},
+		{TaskID: 3, Event: historyDeleted, ActorID: int64Ptr(7)},
+	}
+	uc, _, _, _ := newTestUseCase(r, config.TaskConf{})
+
+	tests := []struct {
+		name   string
+		taskID int64
+		userID int64
+		code   infra_errors.ErrorCode
+	}{
+		{"owner", 1, 7, 0},
+		{"assignee", 1, 8, 0},
+		{"kolaborator view", 1, 9, 0},
+		{"user lain", 1, 10, infra_errors.TASK_FORBIDDEN},
+		{"owner task arsip", 2, 7, 0},
+		{"user lain task arsip", 2, 10, infra_errors.TASK_FORBIDDEN},
+		{"owner task yang dihapus", 3, 7, 0},
+		{"user lain task yang dihapus", 3, 10, infra_errors.TASK_FORBIDDEN},
+		{"task tidak ada", 4, 7, infra_errors.TASK_NOT_FOUND},
+		{"tanpa user", 1, 0, infra_errors.DATA_INVALID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.GetTaskTimeline(&dto.TimelineReqDTO{ID: tt.taskID, UserID: tt.userID})
+			if tt.code == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.code, errorCode(err))
+		})
+	}
+}
+
+func TestSeriesChangesRecorded(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		seriesID int64
+		userID   int64
+		update   bool // UpdateSeries, selain itu StopSeries
+		code     infra_errors.ErrorCode
+		events   []string // riwayat occurrence terakhir (task 2)
+		newValue string
+	}{
+		{"update oleh owner", 5, 7, true, 0, []string{historySeriesUpdated},
+			`{"title":"lari","rrule":"FREQ=DAILY","timezone":"UTC","series_id":5}`},
+		{"stop oleh owner", 5, 7, false, 0, []string{historySeriesStopped}, `{"active":false,"series_id":5}`},
+		{"stop oleh user lain", 5, 8, false, infra_errors.TASK_FORBIDDEN, []string{}, ""},
+		{"stop series tanpa occurrence", 6, 7, false, 0, []string{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(
+				dto.TaskDTO{ID: 1, UserID: 7, SeriesID: int64Ptr(5), ExpiresAt: start.AddDate(0, 0, 1)},
+				dto.TaskDTO{ID: 2, UserID: 7, SeriesID: int64Ptr(5), ExpiresAt: start.AddDate(0, 0, 2)},
+			)
+			r.series[5] = &dto.TaskSeriesDTO{ID: 5, UserID: 7, Title: "olahraga", RRule: "FREQ=DAILY", Timezone: "UTC", DTStart: start, Active: true}
+			r.series[6] = &dto.TaskSeriesDTO{ID: 6, UserID: 7, Title: "tanpa occurrence", RRule: "FREQ=DAILY", Timezone: "UTC", DTStart: start, Active: true}
+			uc, _, _, _ := newTestUseCase(r, config.TaskConf{})
+
+			var err error
+			if tt.update {
+				err = uc.UpdateSeries(&dto.UpdateSeriesReqDTO{ID: tt.seriesID, UserID: tt.userID, Title: "lari"})
+			} else {
+				err = uc.StopSeries(&dto.StopSeriesReqDTO{ID: tt.seriesID, UserID: tt.userID})
+			}
+
+			assert.Equal(t, tt.code, errorCode(err))
+			assert.Equal(t, tt.events, r.events(2))
+			assert.Empty(t, r.events(1))
+			if tt.code == 0 && !tt.update {
+				assert.False(t, r.series[tt.seriesID].Active)
+			}
+			if tt.newValue != "" {
+				assert.JSONEq(t, tt.newValue, string(r.history[0].NewValue))
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		actorID   int64
+		meta      dto.EventMetaDTO
+		oldValue  fields
+		oldJSON   string
+		actor     *int64
+		messageID *string
+	}{
+		{"perubahan oleh user", 7, dto.EventMetaDTO{Source: "finishtask", MessageID: "msg-1"}, fields{"status": "pending"}, `{"status":"pending"}`, int64Ptr(7), strPtr("msg-1")},
+		{"perubahan otomatis", 0, dto.EventMetaDTO{Source: taskConst.SOURCE_SCHEDULER}, nil, `{}`, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7})
+
+			err := r.WithTransaction(func(tx repo.TaskRepository) error {
+				return record(tx, 1, historyFinished, tt.actorID, tt.meta, tt.oldValue, fields{"status": "done"})
+			})
+
+			assert.NoError(t, err)
+			h := r.history[0]
+			assert.Equal(t, tt.actor, h.ActorID)
+			assert.Equal(t, tt.messageID, h.MessageID)
+			assert.Equal(t, tt.meta.Source, h.Source)
+			assert.JSONEq(t, tt.oldJSON, string(h.OldValue))
+			assert.JSONEq(t, `{"status":"done"}`, string(h.NewValue))
+		})
+	}
+
+	// Riwayat ikut di-rollback bersama perubahan task jika transaksi gagal
+	r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7})
+	err := r.WithTransaction(func(tx repo.TaskRepository) error {
+		if err := record(tx, 1, historyFinished, 7, dto.EventMetaDTO{}, nil, fields{"status": "done"}); err != nil {
+			return err
+		}
+		return errors.New("db down")
+	})
+	assert.Error(t, err)
+	assert.Empty(t, r.history)
+}
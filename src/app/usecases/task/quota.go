@@ -0,0 +1,62 @@
+package task
+
+import (
+	"fmt"
+	"log"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	infra_errors "todo_list_consumer/src/infra/errors"
+	"todo_list_consumer/src/infra/metrics"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// Jenis batas kuota, dipakai sebagai label metrics
+const (
+	quotaPending = "pending"
+	quotaCreated = "created"
+)
+
+// checkQuota menolak pembuatan task jika user sudah mencapai batas task pending atau batas
+// pembuatan task dalam CreateWindow. Dipanggil di dalam transaksi pembuatan task
+func (uc *taskUseCase) checkQuota(r repo.TaskRepository, userID int64) error {
+	usage, err := r.GetQuotaUsage(userID, uc.Clock.Now().Add(-uc.Conf.CreateWindow))
+	if err != nil {
+		return err
+	}
+
+	limit, max := quotaExceeded(usage, uc.Conf)
+	if limit == "" {
+		return nil
+	}
+
+	metrics.QuotaRejected(limit)
+	log.Printf("Task user ID %d ditolak: kuota %s (%d) tercapai", userID, limit, max)
+
+	return infra_errors.NewError(infra_errors.TASK_QUOTA_EXCEEDED, fmt.Errorf("kuota task %s user sudah mencapai %d", limit, max))
+}
+
+// quotaExceeded mengembalikan jenis batas yang terlewati beserta nilainya, "" jika masih di bawah kuota.
+// Override per user menggantikan default config, nilai 0 berarti tanpa batas
+func quotaExceeded(usage *dto.QuotaUsageDTO, conf config.TaskConf) (string, int) {
+	maxPending := conf.MaxPending
+	if usage.MaxPending != nil {
+		maxPending = *usage.MaxPending
+	}
+
+	if maxPending > 0 && usage.Pending >= maxPending {
+		return quotaPending, maxPending
+	}
+
+	maxCreated := conf.MaxCreated
+	if usage.MaxCreated != nil {
+		maxCreated = *usage.MaxCreated
+	}
+
+	if maxCreated > 0 && usage.Created >= maxCreated {
+		return quotaCreated, maxCreated
+	}
+
+	return "", 0
+}
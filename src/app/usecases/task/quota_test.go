@@ -0,0 +1,34 @@
+package task
+
+import (
+	"testing"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaExceeded(t *testing.T) {
+	conf := config.TaskConf{MaxPending: 10, MaxCreated: 5}
+	zero, twenty := 0, 20
+
+	tests := []struct {
+		name  string
+		usage dto.QuotaUsageDTO
+		limit string
+	}{
+		{"di bawah kuota", dto.QuotaUsageDTO{Pending: 9, Created: 4}, ""},
+		{"pending penuh", dto.QuotaUsageDTO{Pending: 10}, quotaPending},
+		{"rate penuh", dto.QuotaUsageDTO{Pending: 1, Created: 5}, quotaCreated},
+		{"override lebih besar", dto.QuotaUsageDTO{Pending: 15, MaxPending: &twenty}, ""},
+		{"override tanpa batas", dto.QuotaUsageDTO{Pending: 500, Created: 500, MaxPending: &zero, MaxCreated: &zero}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, _ := quotaExceeded(&tt.usage, conf)
+			assert.Equal(t, tt.limit, limit)
+		})
+	}
+}
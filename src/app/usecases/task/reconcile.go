@@ -0,0 +1,190 @@
+package task
+
+import (
+	"context"
+	"log"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	"todo_list_consumer/src/infra/metrics"
+)
+
+// Tindakan reconciler untuk satu task pending
+const (
+	reconcileNone       = ""
+	reconcileExpire     = "expire"
+	reconcileReschedule = "reschedule"
+)
+
+// ReconcileTasks menyamakan jadwal expire di scheduler dengan task pending di Postgres, misalnya setelah
+// Redis di-flush atau worker mati. Task yang deadline-nya terlewat diproses lewat ExpireTask (mengikuti
+// kebijakan expiry-nya), jadwal yang hilang dibuat ulang, dan jadwal expire maupun reminder milik task
+// yang sudah tidak pending dihapus. Hanya satu replica yang menjalankan reconciler pada satu waktu,
+// replica lain melewati putarannya
+func (uc *taskUseCase) ReconcileTasks() (*dto.ReconcileReportDTO, error) {
+	report := &dto.ReconcileReportDTO{}
+
+	locked, err := uc.Repo.WithReconcileLock(func() error {
+		return uc.reconcile(report)
+	})
+	report.Skipped = err == nil && !locked
+
+	return report, err
+}
+
+// reconcile menjalankan satu putaran reconciler, dipanggil selama memegang lock reconciler
+func (uc *taskUseCase) reconcile(report *dto.ReconcileReportDTO) error {
+	// Jadwal dibaca sebelum task agar task yang dibuat di tengah putaran tidak dianggap orphan
+	scheduledIDs, err := uc.Scheduler.ScheduledTaskIDs()
+	if err != nil {
+		return err
+	}
+
+	reminderIDs, err := uc.Scheduler.ReminderTaskIDs()
+	if err != nil {
+		return err
+	}
+
+	orphans := make(map[int64]bool, len(scheduledIDs))
+	for _, id := range scheduledIDs {
+		orphans[id] = true
+	}
+
+	orphanReminders := make(map[int64]bool, len(reminderIDs))
+	for _, id := range reminderIDs {
+		orphanReminders[id] = true
+	}
+
+	now := uc.Clock.Now()
+	meta := dto.EventMetaDTO{Source: taskConst.SOURCE_RECONCILER}
+
+	var afterID int64
+	for {
+		tasks, err := uc.Repo.GetPendingTasks(afterID, uc.Conf.ReconcileBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			afterID = task.ID
+			scheduled := orphans[task.ID]
+			delete(orphans, task.ID)
+			delete(orphanReminders, task.ID)
+
+			uc.reconcileTask(task, scheduled, now, meta, report)
+		}
+
+		if len(tasks) < uc.Conf.ReconcileBatchSize {
+			break
+		}
+	}
+
+	for id := range orphans {
+		if err := uc.Scheduler.CancelTaskCancellation(id); err != nil {
+			report.Failed++
+			continue
+		}
+
+		report.Orphaned++
+	}
+
+	for id := range orphanReminders {
+		if err := uc.Scheduler.CancelTaskReminders(id); err != nil {
+			report.Failed++
+			continue
+		}
+
+		report.Orphaned++
+	}
+
+	return nil
+}
+
+// reconcileTask menjalankan tindakan reconciler untuk satu task dan mencatatnya di report
+func (uc *taskUseCase) reconcileTask(task dto.PendingTaskDTO, scheduled bool, now time.Time, meta dto.EventMetaDTO, report *dto.ReconcileReportDTO) {
+	action, deadline := reconcileAction(task, scheduled, uc.Conf, now)
+
+	switch action {
+	case reconcileExpire:
+		if err := uc.ExpireTask(&dto.ExpireTaskReqDTO{ID: task.ID, Meta: meta}); err != nil {
+			log.Printf("Reconciler gagal memproses deadline task ID %d: %+v", task.ID, err)
+			report.Failed++
+			return
+		}
+
+		report.Expired++
+	case reconcileReschedule:
+		// Masa tenggang tidak punya reminder, cukup jadwal expire-nya yang dibuat ulang
+		if task.Overdue && task.ExpiryPolicy == taskConst.EXPIRY_POLICY_GRACE {
+			if err := uc.Scheduler.ExtendTaskCancellation(task.ID, deadline); err != nil {
+				report.Failed++
+				return
+			}
+		} else {
+			uc.reschedule(task.ID, deadline)
+		}
+
+		report.Rescheduled++
+	}
+}
+
+// reconcileAction menentukan tindakan untuk task pending beserta deadline yang seharusnya dijadwalkan.
+// Task notify_only yang sudah overdue memang dibiarkan pending tanpa jadwal, sedangkan task grace
+// yang sudah overdue dijadwalkan ke akhir masa tenggangnya. Task yang masih punya jadwal tidak disentuh
+// karena worker scheduler yang akan memprosesnya
+func reconcileAction(task dto.PendingTaskDTO, scheduled bool, conf config.TaskConf, now time.Time) (string, time.Time) {
+	deadline := task.ExpiresAt
+	if task.Overdue {
+		switch task.ExpiryPolicy {
+		case taskConst.EXPIRY_POLICY_NOTIFY_ONLY:
+			return reconcileNone, deadline
+		case taskConst.EXPIRY_POLICY_GRACE:
+			deadline = deadline.Add(conf.GracePeriod)
+		}
+	}
+
+	if scheduled {
+		return reconcileNone, deadline
+	}
+
+	if deadline.After(now) {
+		return reconcileReschedule, deadline
+	}
+
+	return reconcileExpire, deadline
+}
+
+// StartReconciler menjalankan ReconcileTasks sekali saat startup lalu setiap ReconcileInterval
+// sampai ctx dibatalkan. ReconcileInterval 0 berarti hanya dijalankan saat startup
+func (uc *taskUseCase) StartReconciler(ctx context.Context) {
+	for {
+		report, err := uc.ReconcileTasks()
+		if err != nil {
+			log.Println("Gagal merekonsiliasi jadwal task:", err)
+		}
+
+		if report.Skipped {
+			log.Println("Rekonsiliasi jadwal task dilewati, reconciler sedang berjalan di replica lain")
+		} else {
+			log.Printf("Rekonsiliasi jadwal task: %d expired, %d dijadwalkan ulang, %d jadwal orphan dihapus, %d gagal",
+				report.Expired, report.Rescheduled, report.Orphaned, report.Failed)
+
+			metrics.Reconciled("expired", report.Expired)
+			metrics.Reconciled("rescheduled", report.Rescheduled)
+			metrics.Reconciled("orphaned", report.Orphaned)
+			metrics.Reconciled("failed", report.Failed)
+		}
+
+		if uc.Conf.ReconcileInterval <= 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(uc.Conf.ReconcileInterval):
+		}
+	}
+}
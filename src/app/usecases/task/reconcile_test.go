@@ -0,0 +1,111 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileAction(t *testing.T) {
+	conf := config.TaskConf{GracePeriod: time.Hour}
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name      string
+		task      dto.PendingTaskDTO
+		scheduled bool
+		action    string
+		deadline  time.Time
+	}{
+		{"jadwal masih ada", dto.PendingTaskDTO{ExpiresAt: future}, true, reconcileNone, future},
+		{"jadwal hilang", dto.PendingTaskDTO{ExpiresAt: future}, false, reconcileReschedule, future},
+		{"deadline terlewat", dto.PendingTaskDTO{ExpiresAt: past}, false, reconcileExpire, past},
+		{"notify only belum overdue", dto.PendingTaskDTO{ExpiresAt: past, ExpiryPolicy: taskConst.EXPIRY_POLICY_NOTIFY_ONLY}, false, reconcileExpire, past},
+		{"notify only sudah overdue", dto.PendingTaskDTO{ExpiresAt: past, ExpiryPolicy: taskConst.EXPIRY_POLICY_NOTIFY_ONLY, Overdue: true}, false, reconcileNone, past},
+		{"grace dalam masa tenggang", dto.PendingTaskDTO{ExpiresAt: past, ExpiryPolicy: taskConst.EXPIRY_POLICY_GRACE, Overdue: true}, false, reconcileReschedule, past.Add(time.Hour)},
+		{"grace masa tenggang habis", dto.PendingTaskDTO{ExpiresAt: past.Add(-time.Hour), ExpiryPolicy: taskConst.EXPIRY_POLICY_GRACE, Overdue: true}, false, reconcileExpire, past},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, deadline := reconcileAction(tt.task, tt.scheduled, conf, now)
+			assert.Equal(t, tt.action, action)
+			assert.Equal(t, tt.deadline, deadline)
+		})
+	}
+}
+
+func TestReconcileTasks(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	newRepo := func() *fakeRepo {
+		return newFakeRepo(
+			dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour)},
+			dto.TaskDTO{ID: 2, UserID: 7, ExpiresAt: now.Add(-time.Hour), Status: "done"},
+			dto.TaskDTO{ID: 3, UserID: 7, ExpiresAt: now.Add(time.Hour)},
+		)
+	}
+	newSchedule := func(s *fakeScheduler) {
+		s.expiries[1] = now.Add(time.Hour)
+		s.reminders[1] = now.Add(time.Hour)
+		s.expiries[2] = now.Add(-time.Hour)
+		s.reminders[2] = now.Add(-time.Hour)
+		s.reminders[4] = now.Add(time.Hour)
+	}
+
+	t.Run("jadwal orphan dan reminder orphan dihapus", func(t *testing.T) {
+		r := newRepo()
+		uc, s, _, _ := newTestUseCase(r, config.TaskConf{ReconcileBatchSize: 2})
+		newSchedule(s)
+
+		report, err := uc.ReconcileTasks()
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.ReconcileReportDTO{Rescheduled: 1, Orphaned: 3}, report)
+		assert.Equal(t, map[int64]time.Time{1: now.Add(time.Hour), 3: now.Add(time.Hour)}, s.expiries)
+		assert.Equal(t, map[int64]time.Time{1: now.Add(time.Hour), 3: now.Add(time.Hour)}, s.reminders)
+	})
+
+	t.Run("putaran dilewati jika lock dipegang replica lain", func(t *testing.T) {
+		r := newRepo()
+		r.lockHeld = true
+		uc, s, _, _ := newTestUseCase(r, config.TaskConf{ReconcileBatchSize: 2})
+		newSchedule(s)
+
+		report, err := uc.ReconcileTasks()
+		assert.NoError(t, err)
+		assert.True(t, report.Skipped)
+		assert.Len(t, s.expiries, 2)
+		assert.Len(t, s.reminders, 3)
+	})
+
+	t.Run("keputusan mengikuti clock", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour)})
+		uc, s, _, c := newTestUseCase(r, config.TaskConf{ReconcileBatchSize: 2})
+
+		// Jadwal hilang sebelum deadline sehingga dibuat ulang
+		report, err := uc.ReconcileTasks()
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.ReconcileReportDTO{Rescheduled: 1}, report)
+		assert.Equal(t, now.Add(time.Hour), s.expiries[1])
+
+		// Jadwal yang masih ada tidak disentuh walau deadline sudah lewat, worker yang memprosesnya
+		c.Advance(2 * time.Hour)
+		report, err = uc.ReconcileTasks()
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.ReconcileReportDTO{}, report)
+		assert.Equal(t, "pending", r.tasks[1].Status)
+
+		// Jadwal hilang setelah deadline lewat sehingga task di-expire
+		delete(s.expiries, 1)
+		report, err = uc.ReconcileTasks()
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.ReconcileReportDTO{Expired: 1}, report)
+		assert.Equal(t, "expired", r.tasks[1].Status)
+		assert.Equal(t, []string{historyExpired}, r.events(1))
+	})
+}
@@ -0,0 +1,53 @@
+package task
+
+import (
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+
+	"github.com/teambition/rrule-go"
+)
+
+// buildRule mem-parsing RRULE dan mengikatnya ke DTSTART pada timezone series,
+// sehingga "BYHOUR=9" berarti jam 9 waktu lokal user, bukan UTC
+func buildRule(rule string, timezone string, dtstart time.Time) (*rrule.RRule, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := rrule.StrToROptionInLocation(rule, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	opt.Dtstart = dtstart.In(loc)
+	return rrule.NewRRule(*opt)
+}
+
+// seriesStart menentukan DTSTART untuk series baru. Tanpa expires_at, DTSTART diambil dari
+// tengah malam hari ini agar jam/menit yang tidak disebut di RRULE bernilai 0
+func seriesStart(expiresAt time.Time, timezone string, now time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !expiresAt.IsZero() {
+		return expiresAt.In(loc), nil
+	}
+
+	now = now.In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), nil
+}
+
+// nextOccurrence mengembalikan occurrence pertama setelah waktu after.
+// Nilai zero berarti series sudah selesai (COUNT/UNTIL tercapai)
+func nextOccurrence(series *dto.TaskSeriesDTO, after time.Time) (time.Time, error) {
+	rule, err := buildRule(series.RRule, series.Timezone, series.DTStart)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return rule.After(after, false), nil
+}
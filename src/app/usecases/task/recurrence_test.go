@@ -0,0 +1,53 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextOccurrenceUsesSeriesTimezone(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC) // Rabu
+	start, err := seriesStart(time.Time{}, "Asia/Jakarta", now)
+	assert.NoError(t, err)
+
+	series := &dto.TaskSeriesDTO{
+		RRule:    "FREQ=WEEKLY;BYDAY=MO;BYHOUR=9",
+		Timezone: "Asia/Jakarta",
+		DTStart:  start,
+	}
+
+	next, err := nextOccurrence(series, now)
+	if assert.NoError(t, err) {
+		// Senin 17 Maret 2025 09:00 WIB = 02:00 UTC
+		assert.Equal(t, time.Date(2025, 3, 17, 2, 0, 0, 0, time.UTC), next.UTC())
+	}
+
+	following, err := nextOccurrence(series, next)
+	if assert.NoError(t, err) {
+		assert.Equal(t, next.Add(7*24*time.Hour), following)
+	}
+}
+
+func TestNextOccurrenceEndsAfterCount(t *testing.T) {
+	series := &dto.TaskSeriesDTO{
+		RRule:    "FREQ=DAILY;COUNT=2",
+		Timezone: "UTC",
+		DTStart:  time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	next, err := nextOccurrence(series, time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.True(t, next.IsZero())
+}
+
+func TestBuildRuleRejectsInvalidInput(t *testing.T) {
+	_, err := buildRule("FREQ=SOMETIMES", "UTC", time.Now())
+	assert.Error(t, err)
+
+	_, err = buildRule("FREQ=DAILY", "Mars/Olympus", time.Now())
+	assert.Error(t, err)
+}
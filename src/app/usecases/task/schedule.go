@@ -0,0 +1,87 @@
+package task
+
+import (
+	"log"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// RemindTask dipanggil worker scheduler ketika key reminder jatuh tempo.
+// Reminder untuk task yang sudah tidak pending diabaikan
+func (uc *taskUseCase) RemindTask(req *dto.RemindTaskReqDTO) error {
+	task, err := uc.Repo.GetTask(req.ID)
+	if err == repo.ErrTaskNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if task.Status != "pending" {
+		return nil
+	}
+
+	remaining := task.ExpiresAt.Sub(uc.Clock.Now())
+	if remaining <= 0 {
+		return nil
+	}
+
+	payload := dto.TaskEventDTO{
+		Event:            taskConst.TASK_REMINDER_EVENT,
+		Task:             task,
+		RemainingSeconds: int64(remaining.Round(time.Second) / time.Second),
+		OffsetSeconds:    int64(req.Offset / time.Second),
+	}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		return record(r, task.ID, historyReminded, 0, req.Meta, fields{},
+			fields{"offset_seconds": payload.OffsetSeconds, "remaining_seconds": payload.RemainingSeconds})
+	})
+	if err != nil {
+		return err
+	}
+
+	return uc.publish(payload)
+}
+
+// schedule menjadwalkan expiry dan reminder untuk task baru
+func (uc *taskUseCase) schedule(taskID int64, expiresAt time.Time) {
+	if err := uc.Scheduler.ScheduleTaskCancellation(taskID, expiresAt); err != nil {
+		log.Println("Gagal menjadwalkan pembatalan task:", err)
+	}
+
+	if err := uc.Scheduler.ScheduleTaskReminders(taskID, expiresAt, uc.Conf.ReminderOffsets); err != nil {
+		log.Println("Gagal menjadwalkan reminder task:", err)
+	}
+}
+
+// reschedule memindahkan expiry task yang deadline-nya berubah dan membuat ulang reminder-nya
+func (uc *taskUseCase) reschedule(taskID int64, expiresAt time.Time) {
+	if err := uc.Scheduler.ExtendTaskCancellation(taskID, expiresAt); err != nil {
+		log.Println("Gagal memperpanjang jadwal task:", err)
+	}
+
+	if err := uc.Scheduler.CancelTaskReminders(taskID); err != nil {
+		log.Println("Gagal membatalkan reminder task:", err)
+	}
+
+	if err := uc.Scheduler.ScheduleTaskReminders(taskID, expiresAt, uc.Conf.ReminderOffsets); err != nil {
+		log.Println("Gagal menjadwalkan reminder task:", err)
+	}
+}
+
+// cancelSchedule menghapus jadwal expire dan reminder task yang sudah tidak pending
+func (uc *taskUseCase) cancelSchedule(taskID int64) {
+	if err := uc.Scheduler.CancelTaskCancellation(taskID); err != nil {
+		log.Println("Gagal membatalkan jadwal task:", err)
+	}
+
+	if err := uc.Scheduler.CancelTaskReminders(taskID); err != nil {
+		log.Println("Gagal membatalkan reminder task:", err)
+	}
+}
@@ -0,0 +1,37 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemindTaskPublishesOffset(t *testing.T) {
+	r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: time.Date(2025, 3, 12, 11, 0, 0, 0, time.UTC)})
+	uc, _, p, _ := newTestUseCase(r, config.TaskConf{})
+
+	meta := dto.EventMetaDTO{Source: taskConst.SOURCE_SCHEDULER, MessageID: "task:1:remind:3600"}
+	assert.NoError(t, uc.RemindTask(&dto.RemindTaskReqDTO{ID: 1, Offset: time.Hour, Meta: meta}))
+	if assert.Len(t, p.payloads, 1) {
+		assert.Equal(t, taskConst.TASK_REMINDER_EVENT, p.payloads[0].Event)
+		assert.Equal(t, int64(3600), p.payloads[0].OffsetSeconds)
+		assert.Equal(t, int64(3600), p.payloads[0].RemainingSeconds)
+	}
+
+	// Reminder dicatat di riwayat beserta key scheduler yang memicunya
+	if assert.Equal(t, []string{historyReminded}, r.events(1)) {
+		assert.Equal(t, taskConst.SOURCE_SCHEDULER, r.history[0].Source)
+		assert.Equal(t, "task:1:remind:3600", *r.history[0].MessageID)
+	}
+
+	// Task yang sudah tidak pending tidak mendapat reminder
+	r.tasks[1].Status = "done"
+	assert.NoError(t, uc.RemindTask(&dto.RemindTaskReqDTO{ID: 1, Offset: time.Hour}))
+	assert.Len(t, p.payloads, 1)
+	assert.Len(t, r.history, 1)
+}
@@ -0,0 +1,146 @@
+package task
+
+import (
+	"errors"
+	"log"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// AssignTask menugaskan task ke user lain dan/atau mengatur kolaboratornya. Hanya owner yang boleh
+func (uc *taskUseCase) AssignTask(req *dto.AssignTaskReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeOwner(task, req.UserID); err != nil {
+		return err
+	}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if req.AssigneeID != nil {
+			if err := r.AssignTask(task.ID, *req.AssigneeID); err != nil {
+				return err
+			}
+		}
+
+		if err := r.AddMembers(task.ID, req.Collaborators); err != nil {
+			return err
+		}
+
+		if err := r.RemoveMembers(task.ID, req.RemoveCollaborators); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyAssigned, req.UserID, req.Meta, fields{"assignee_id": task.AssigneeID}, fields{
+			"assignee_id":          req.AssigneeID,
+			"collaborators":        req.Collaborators,
+			"remove_collaborators": req.RemoveCollaborators,
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Perubahan kolaborator saja tetap tercatat di riwayat, event hanya dikirim jika assignee berganti
+	if assigneeChanged(task.AssigneeID, req.AssigneeID) {
+		uc.notifyTransition(taskConst.TASK_ASSIGNED_EVENT, "", task.ID)
+	}
+
+	return nil
+}
+
+// assigneeChanged bernilai true jika request mengisi assignee yang berbeda dari assignee saat ini
+func assigneeChanged(current *int64, requested *int64) bool {
+	if requested == nil {
+		return false
+	}
+
+	return current == nil || *current != *requested
+}
+
+// authorize memastikan user boleh mengubah atau menyelesaikan task:
+// owner, assignee, atau kolaborator dengan permission complete
+func (uc *taskUseCase) authorize(task *dto.TaskDTO, userID int64) error {
+	if userID != 0 && (task.UserID == userID || (task.AssigneeID != nil && *task.AssigneeID == userID)) {
+		return nil
+	}
+
+	members, err := uc.Repo.GetMembers(task.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.UserID == userID && member.Permission == taskConst.PERMISSION_COMPLETE {
+			return nil
+		}
+	}
+
+	return forbidden()
+}
+
+// authorizeView memastikan user boleh melihat task: owner, assignee atau kolaborator dengan permission apa pun
+func (uc *taskUseCase) authorizeView(task *dto.TaskDTO, userID int64) error {
+	if task.UserID == userID || (task.AssigneeID != nil && *task.AssigneeID == userID) {
+		return nil
+	}
+
+	members, err := uc.Repo.GetMembers(task.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.UserID == userID {
+			return nil
+		}
+	}
+
+	return forbidden()
+}
+
+// authorizeOwner memastikan aksi hanya dilakukan oleh owner task
+func authorizeOwner(task *dto.TaskDTO, userID int64) error {
+	if task.UserID != userID {
+		return forbidden()
+	}
+
+	return nil
+}
+
+func forbidden() error {
+	return infra_errors.NewError(infra_errors.TASK_FORBIDDEN, errors.New("user tidak memiliki akses ke task ini"))
+}
+
+// recipients mengumpulkan semua user yang perlu menerima notifikasi task tanpa duplikat
+func (uc *taskUseCase) recipients(task *dto.TaskDTO) []int64 {
+	users := []int64{task.UserID}
+	if task.AssigneeID != nil && *task.AssigneeID != task.UserID {
+		users = append(users, *task.AssigneeID)
+	}
+
+	members, err := uc.Repo.GetMembers(task.ID)
+	if err != nil {
+		log.Println("Gagal mengambil kolaborator task:", err)
+		return users
+	}
+
+	for _, member := range members {
+		if member.UserID != task.UserID && (task.AssigneeID == nil || member.UserID != *task.AssigneeID) {
+			users = append(users, member.UserID)
+		}
+	}
+
+	return users
+}
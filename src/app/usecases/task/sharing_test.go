@@ -0,0 +1,131 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTaskAuthorizesParentAndBlockers(t *testing.T) {
+	expiresAt := dto.Timestamp{Time: time.Date(2025, 3, 13, 10, 0, 0, 0, time.UTC)}
+	newRepo := func() *fakeRepo {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7}, dto.TaskDTO{ID: 2, UserID: 8})
+		r.members[2] = []dto.CollaboratorDTO{{UserID: 9, Permission: taskConst.PERMISSION_COMPLETE}}
+		return r
+	}
+
+	tests := []struct {
+		name string
+		req  dto.CreateTaskReqDTO
+		code infra_errors.ErrorCode
+	}{
+		{"subtask di task sendiri", dto.CreateTaskReqDTO{UserID: 7, ParentID: int64Ptr(1)}, 0},
+		{"subtask di task user lain", dto.CreateTaskReqDTO{UserID: 8, ParentID: int64Ptr(1)}, infra_errors.TASK_FORBIDDEN},
+		{"blocker milik user lain", dto.CreateTaskReqDTO{UserID: 7, BlockedBy: []int64{2}}, infra_errors.TASK_FORBIDDEN},
+		{"blocker dengan hak complete", dto.CreateTaskReqDTO{UserID: 9, BlockedBy: []int64{2}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRepo()
+			uc, _, _, _ := newTestUseCase(r, config.TaskConf{})
+
+			req := tt.req
+			req.Title = "Subtask"
+			req.ExpiresAt = expiresAt
+
+			err := uc.AddTask(&req)
+			assert.Equal(t, tt.code, errorCode(err))
+			if tt.code != 0 {
+				assert.Len(t, r.tasks, 2)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, r.tasks, 3)
+			}
+		})
+	}
+}
+
+func TestFinishTaskAuthorization(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    int64
+		anonymous bool // config AllowAnonymousFinish
+		code      infra_errors.ErrorCode
+	}{
+		{"owner", 7, false, 0},
+		{"kolaborator complete", 9, false, 0},
+		{"kolaborator view", 10, false, infra_errors.TASK_FORBIDDEN},
+		{"user lain", 8, false, infra_errors.TASK_FORBIDDEN},
+		{"tanpa user_id pada task bersama", 0, false, infra_errors.DATA_INVALID},
+		{"tanpa user_id dengan mode producer lama", 0, true, 0},
+		{"user lain tetap dicek dengan mode producer lama", 8, true, infra_errors.TASK_FORBIDDEN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7})
+			r.members[1] = []dto.CollaboratorDTO{
+				{UserID: 9, Permission: taskConst.PERMISSION_COMPLETE},
+				{UserID: 10, Permission: taskConst.PERMISSION_VIEW},
+			}
+			uc, _, _, _ := newTestUseCase(r, config.TaskConf{AllowAnonymousFinish: tt.anonymous})
+
+			err := uc.FinishTask(&dto.FinishtTaskReqDTO{ID: 1, UserID: tt.userID})
+			assert.Equal(t, tt.code, errorCode(err))
+			if tt.code != 0 {
+				assert.Equal(t, "pending", r.tasks[1].Status)
+				assert.Empty(t, r.history)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "done", r.tasks[1].Status)
+			if tt.userID == 0 {
+				assert.Nil(t, r.history[0].ActorID)
+			}
+		})
+	}
+}
+
+func TestAssignTaskPublishesOnlyOnAssigneeChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   *int64
+		requested *int64
+		published bool
+	}{
+		{"assignee baru", nil, int64Ptr(8), true},
+		{"assignee diganti", int64Ptr(8), int64Ptr(9), true},
+		{"assignee sama", int64Ptr(8), int64Ptr(8), false},
+		{"hanya kolaborator", int64Ptr(8), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, AssigneeID: tt.current})
+			uc, _, p, _ := newTestUseCase(r, config.TaskConf{})
+
+			err := uc.AssignTask(&dto.AssignTaskReqDTO{
+				ID:            1,
+				UserID:        7,
+				AssigneeID:    tt.requested,
+				Collaborators: []dto.CollaboratorDTO{{UserID: 10, Permission: taskConst.PERMISSION_VIEW}},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{historyAssigned}, r.events(1))
+			if tt.published {
+				assert.Equal(t, []string{taskConst.TASK_ASSIGNED_EVENT}, p.subjects)
+			} else {
+				assert.Empty(t, p.subjects)
+			}
+		})
+	}
+}
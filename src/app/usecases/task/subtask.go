@@ -0,0 +1,151 @@
+package task
+
+import (
+	"log"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// Alasan transisi otomatis yang dikirim pada TaskEventDTO
+const (
+	reasonChildrenDone  = "children_done"
+	reasonParentExpired = "parent_expired"
+	reasonParentDeleted = "parent_deleted"
+	// reasonParentArchived hanya tercatat di riwayat, ditulis langsung oleh query ArchiveTasks
+	reasonParentArchived = "parent_archived"
+)
+
+// computeStatus menghitung status efektif task secara rekursif. Task yang memiliki subtask
+// dianggap "done" jika seluruh subtask beserta turunannya juga "done"
+func (uc *taskUseCase) computeStatus(task *dto.TaskDTO) (string, error) {
+	if task.Status != "pending" {
+		return task.Status, nil
+	}
+
+	children, err := uc.Repo.GetChildren(task.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(children) == 0 {
+		return task.Status, nil
+	}
+
+	for i := range children {
+		status, err := uc.computeStatus(&children[i])
+		if err != nil {
+			return "", err
+		}
+
+		if status != "done" {
+			return "pending", nil
+		}
+	}
+
+	return "done", nil
+}
+
+// rollUp menyelesaikan parent secara otomatis (naik terus ke atas) jika semua subtask-nya sudah done
+func (uc *taskUseCase) rollUp(parentID *int64, meta dto.EventMetaDTO) {
+	for parentID != nil {
+		parent, err := uc.Repo.GetTask(*parentID)
+		if err != nil {
+			log.Println("Gagal mengambil parent task:", err)
+			return
+		}
+
+		if parent.Status != "pending" {
+			return
+		}
+
+		status, err := uc.computeStatus(parent)
+		if err != nil {
+			log.Println("Gagal menghitung status parent task:", err)
+			return
+		}
+
+		if status != "done" {
+			return
+		}
+
+		// Parent yang masih diblokir task lain tetap pending walaupun semua subtask selesai
+		if err := uc.checkBlockers(parent.ID); err != nil {
+			log.Printf("Task ID %d tidak diselesaikan otomatis: %+v", parent.ID, err)
+			return
+		}
+
+		err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+			if err := r.FinishTask(&dto.FinishtTaskReqDTO{ID: parent.ID}); err != nil {
+				return err
+			}
+
+			if err := r.CloseSessions(parent.ID, uc.Clock.Now()); err != nil {
+				return err
+			}
+
+			return record(r, parent.ID, historyFinished, 0, meta, fields{"status": parent.Status}, fields{"status": "done", "reason": reasonChildrenDone})
+		})
+
+		if err != nil {
+			log.Println("Gagal menyelesaikan parent task:", err)
+			return
+		}
+
+		log.Printf("Task ID %d otomatis selesai karena semua subtask selesai", parent.ID)
+		uc.cancelSchedule(parent.ID)
+		uc.notifyTransition(taskConst.TASK_FINISHED_EVENT, reasonChildrenDone, parent.ID)
+		uc.scheduleNextOccurrence(parent.ID, meta)
+
+		parentID = parent.ParentID
+	}
+}
+
+// handleChildren menerapkan kebijakan subtask (cascade/detach) untuk subtask yang masih pending
+// ketika parent-nya expired atau dihapus
+func (uc *taskUseCase) handleChildren(parentID int64, deleted bool, meta dto.EventMetaDTO) {
+	children, err := uc.Repo.GetChildren(parentID)
+	if err != nil {
+		log.Println("Gagal mengambil subtask:", err)
+		return
+	}
+
+	reason := reasonParentExpired
+	if deleted {
+		reason = reasonParentDeleted
+	}
+
+	for i := range children {
+		child := &children[i]
+		if child.Status != "pending" {
+			continue
+		}
+
+		switch {
+		case uc.Conf.ChildPolicy == taskConst.CHILD_POLICY_DETACH:
+			err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+				if err := r.DetachTask(child.ID); err != nil {
+					return err
+				}
+
+				return record(r, child.ID, historyDetached, 0, meta, fields{"parent_id": parentID}, fields{"parent_id": nil, "reason": reason})
+			})
+
+			if err != nil {
+				log.Println("Gagal melepas subtask:", err)
+				continue
+			}
+			uc.notifyTransition(taskConst.TASK_DETACHED_EVENT, reason, child.ID)
+		case deleted:
+			if err := uc.deleteTask(child, reason, 0, meta); err != nil {
+				log.Println("Gagal menghapus subtask:", err)
+			}
+		default:
+			if err := uc.expireTask(child.ID, reason, meta); err != nil {
+				log.Println("Gagal meng-expire subtask:", err)
+			}
+		}
+	}
+}
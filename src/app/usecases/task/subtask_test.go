@@ -0,0 +1,115 @@
+package task
+
+import (
+	"testing"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		tasks  []dto.TaskDTO
+		status string
+	}{
+		{"tanpa subtask", []dto.TaskDTO{{ID: 1}}, "pending"},
+		{"task sudah expired", []dto.TaskDTO{{ID: 1, Status: "expired"}, {ID: 2, ParentID: int64Ptr(1)}}, "expired"},
+		{"semua subtask done", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1), Status: "done"},
+			{ID: 3, ParentID: int64Ptr(1), Status: "done"},
+		}, "done"},
+		{"satu subtask pending", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1), Status: "done"},
+			{ID: 3, ParentID: int64Ptr(1)},
+		}, "pending"},
+		{"cucu pending menahan parent", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1)},
+			{ID: 3, ParentID: int64Ptr(2), Status: "done"},
+			{ID: 4, ParentID: int64Ptr(2)},
+		}, "pending"},
+		{"semua cucu done", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1)},
+			{ID: 3, ParentID: int64Ptr(2), Status: "done"},
+			{ID: 4, ParentID: int64Ptr(3), Status: "done"},
+			{ID: 5, ParentID: int64Ptr(1), Status: "done"},
+		}, "done"},
+		{"subtask expired tidak dihitung done", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1), Status: "expired"},
+		}, "pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(tt.tasks...)
+			uc, _, _, _ := newTestUseCase(r, config.TaskConf{})
+
+			status, err := uc.computeStatus(r.tasks[1])
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
+
+func TestRollUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		tasks    []dto.TaskDTO
+		blockers map[int64][]int64
+		from     int64
+		done     []int64
+		pending  []int64
+	}{
+		{"naik sampai root", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1)},
+			{ID: 3, ParentID: int64Ptr(2), Status: "done"},
+		}, nil, 2, []int64{1, 2}, nil},
+		{"berhenti pada saudara yang pending", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1)},
+			{ID: 3, ParentID: int64Ptr(2), Status: "done"},
+			{ID: 4, ParentID: int64Ptr(1)},
+		}, nil, 2, []int64{2}, []int64{1, 4}},
+		{"parent masih diblokir", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1), Status: "done"},
+			{ID: 9},
+		}, map[int64][]int64{1: {9}}, 1, nil, []int64{1, 9}},
+		{"parent sudah tidak pending", []dto.TaskDTO{
+			{ID: 1},
+			{ID: 2, ParentID: int64Ptr(1), Status: "expired"},
+			{ID: 3, ParentID: int64Ptr(2), Status: "done"},
+		}, nil, 2, nil, []int64{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(tt.tasks...)
+			if tt.blockers != nil {
+				r.blockers = tt.blockers
+			}
+			uc, _, p, _ := newTestUseCase(r, config.TaskConf{})
+
+			uc.rollUp(int64Ptr(tt.from), dto.EventMetaDTO{Source: taskConst.SOURCE_SCHEDULER})
+
+			for _, id := range tt.done {
+				assert.Equal(t, "done", r.tasks[id].Status, "task %d", id)
+				assert.Equal(t, []string{historyFinished}, r.events(id))
+			}
+			for _, id := range tt.pending {
+				assert.Equal(t, "pending", r.tasks[id].Status, "task %d", id)
+				assert.Empty(t, r.events(id))
+			}
+			assert.Len(t, p.subjects, len(tt.done))
+		})
+	}
+}
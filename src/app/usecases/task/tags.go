@@ -0,0 +1,102 @@
+package task
+
+import (
+	"strings"
+
+	dto "todo_list_consumer/src/app/dto/task"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// AddTags menambahkan tag ke task yang sudah ada
+func (uc *taskUseCase) AddTags(req *dto.TagsReqDTO) error {
+	req.Tags = normalizeTags(req.Tags)
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	return uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.AddTags(task.ID, req.Tags); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyTagsAdded, req.UserID, req.Meta, fields{"tags": task.Tags}, fields{"tags": req.Tags})
+	})
+}
+
+// RemoveTags melepas tag dari task
+func (uc *taskUseCase) RemoveTags(req *dto.TagsReqDTO) error {
+	req.Tags = normalizeTags(req.Tags)
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	return uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.RemoveTags(task.ID, req.Tags); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyTagsRemoved, req.UserID, req.Meta, fields{"tags": task.Tags}, fields{"tags": req.Tags})
+	})
+}
+
+// ChangePriority mengubah level prioritas task
+func (uc *taskUseCase) ChangePriority(req *dto.ChangePriorityReqDTO) error {
+	req.Priority = strings.ToLower(strings.TrimSpace(req.Priority))
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	return uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.ChangePriority(task.ID, req.Priority); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyPriorityChanged, req.UserID, req.Meta, fields{"priority": task.Priority}, fields{"priority": req.Priority})
+	})
+}
+
+// normalizeTags merapikan tag (trim, huruf kecil) dan membuang duplikat tanpa mengubah urutan
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		result = append(result, tag)
+	}
+
+	return result
+}
@@ -0,0 +1,17 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags := normalizeTags([]string{" Work ", "home", "work", "", "HOME", "urgent"})
+
+	assert.Equal(t, []string{"work", "home", "urgent"}, tags)
+}
+
+func TestNormalizeEmptyTags(t *testing.T) {
+	assert.Empty(t, normalizeTags(nil))
+}
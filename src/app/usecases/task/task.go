@@ -1,54 +1,650 @@
 package task
 
 import (
+	"context"
+	"errors"
 	"log"
+	"strings"
+	"time"
+
 	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
 
 	repo "todo_list_consumer/src/app/repositories/task"
-	rdScheduler "todo_list_consumer/src/infra/persistence/redis/scheduler"
+	publisher "todo_list_consumer/src/infra/broker/nats/publisher"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+
+	validation "github.com/go-ozzo/ozzo-validation"
 )
 
 type TaskUseCase interface {
 	AddTask(req *dto.CreateTaskReqDTO) error
 	FinishTask(req *dto.FinishtTaskReqDTO) error
+	SnoozeTask(req *dto.SnoozeTaskReqDTO) error
+	ExpireTask(req *dto.ExpireTaskReqDTO) error
+	UpdateSeries(req *dto.UpdateSeriesReqDTO) error
+	StopSeries(req *dto.StopSeriesReqDTO) error
+	DeleteTask(req *dto.DeleteTaskReqDTO) error
+	AddTags(req *dto.TagsReqDTO) error
+	RemoveTags(req *dto.TagsReqDTO) error
+	ChangePriority(req *dto.ChangePriorityReqDTO) error
+	RemindTask(req *dto.RemindTaskReqDTO) error
+	AssignTask(req *dto.AssignTaskReqDTO) error
+	AddDependency(req *dto.DependencyReqDTO) error
+	RemoveDependency(req *dto.DependencyReqDTO) error
+	GetTaskTimeline(req *dto.TimelineReqDTO) ([]dto.TaskHistoryDTO, error)
+	SetTimezone(req *dto.SetTimezoneReqDTO) error
+	StartTimer(req *dto.TimerReqDTO) error
+	StopTimer(req *dto.TimerReqDTO) error
+	GetTimeReport(req *dto.TimeReportReqDTO) ([]dto.TimeReportDTO, error)
+	ArchiveTasks() (int64, error)
+	StartArchiver(ctx context.Context)
+	ReconcileTasks() (*dto.ReconcileReportDTO, error)
+	StartReconciler(ctx context.Context)
+	GetTaskSchedule(taskID int64) (*dto.TaskScheduleDTO, error)
+	ListUpcomingExpirations(req *dto.UpcomingExpirationsReqDTO) ([]dto.TaskScheduleDTO, error)
+	ForceExpireTask(req *dto.ExpireTaskReqDTO) error
+	CancelTaskExpiry(req *dto.CancelExpiryReqDTO) error
+	RescheduleTask(req *dto.RescheduleTaskReqDTO) error
 }
 
 type taskUseCase struct {
 	Repo      repo.TaskRepository
-	Scheduler rdScheduler.SchedulerInterface
+	Scheduler infra_scheduler.SchedulerInterface
+	Publisher publisher.PublisherInterface
+	Clock     clock.Clock
+	Conf      config.TaskConf
 }
 
-func NewTaskUseCase(r repo.TaskRepository, s rdScheduler.SchedulerInterface) TaskUseCase {
+func NewTaskUseCase(r repo.TaskRepository, s infra_scheduler.SchedulerInterface, p publisher.PublisherInterface, c clock.Clock, conf config.TaskConf) TaskUseCase {
 	return &taskUseCase{
 		Repo:      r,
 		Scheduler: s,
+		Publisher: p,
+		Clock:     c,
+		Conf:      conf,
 	}
 }
 
 func (uc *taskUseCase) AddTask(req *dto.CreateTaskReqDTO) error {
+	req.Tags = normalizeTags(req.Tags)
+	req.Priority = strings.ToLower(strings.TrimSpace(req.Priority))
+	if req.Priority == "" {
+		req.Priority = taskConst.PRIORITY_MEDIUM
+	}
+
+	req.ExpiryPolicy = strings.ToLower(strings.TrimSpace(req.ExpiryPolicy))
+	if req.ExpiryPolicy == "" {
+		req.ExpiryPolicy = taskConst.EXPIRY_POLICY_EXPIRE
+	}
+
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	if req.Due != "" && !req.ExpiresAt.IsZero() {
+		return validationError(validation.Errors{"due": errors.New("isi salah satu dari expires_at atau due")})
+	}
+
+	// Zona waktu hanya dicari jika dibutuhkan: due, expires_at tanpa offset atau task berulang
+	if req.Due != "" || req.ExpiresAt.Floating || req.RRule != "" {
+		loc, err := uc.userLocation(req.UserID, req.Timezone)
+		if err != nil {
+			return err
+		}
+
+		req.Timezone = loc.String()
+		req.ExpiresAt = dto.Timestamp{Time: resolveTime(req.ExpiresAt, loc)}
+
+		if req.Due != "" {
+			due, err := parseDue(req.Due, uc.Clock.Now().In(loc))
+			if err != nil {
+				return validationError(validation.Errors{"due": err})
+			}
+			req.ExpiresAt = dto.Timestamp{Time: due}
+		}
+	}
+
+	if req.RRule != "" {
+		return uc.addRecurringTask(req)
+	}
+
+	// Subtask hanya boleh dibuat di bawah parent yang masih pending dan bisa diubah oleh pembuatnya
+	if req.ParentID != nil {
+		parent, err := uc.Repo.GetTask(*req.ParentID)
+		if err == repo.ErrTaskNotFound {
+			return infra_errors.NewError(infra_errors.PARENT_TASK_INVALID, err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if parent.Status != "pending" {
+			return infra_errors.NewError(infra_errors.PARENT_TASK_INVALID, errors.New("parent task sudah tidak pending"))
+		}
+
+		if err := uc.authorize(parent, req.UserID); err != nil {
+			return err
+		}
+	}
+
+	// Task milik user lain tidak boleh dijadikan blocker tanpa hak akses ke task tersebut
+	for _, blockerID := range req.BlockedBy {
+		blocker, err := uc.getTask(blockerID)
+		if err != nil {
+			return err
+		}
 
-	resp, err := uc.Repo.AddTask(req)
+		if err := uc.authorize(blocker, req.UserID); err != nil {
+			return err
+		}
+	}
+
+	var resp *dto.CreateTaskRespDTO
+	err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := uc.checkQuota(r, req.UserID); err != nil {
+			return err
+		}
+
+		var err error
+		resp, err = r.AddTask(req)
+		if err != nil {
+			return err
+		}
+
+		if err := r.AddTags(resp.ID, req.Tags); err != nil {
+			return err
+		}
+
+		for _, blockerID := range req.BlockedBy {
+			if err := addDependency(r, resp.ID, blockerID); err != nil {
+				return err
+			}
+		}
+
+		return record(r, resp.ID, historyCreated, req.UserID, req.Meta, nil, fields{
+			"title":         req.Title,
+			"priority":      req.Priority,
+			"tags":          req.Tags,
+			"expires_at":    req.ExpiresAt.Time,
+			"expiry_policy": req.ExpiryPolicy,
+			"parent_id":     req.ParentID,
+			"blocked_by":    req.BlockedBy,
+		})
+	})
 
 	if err != nil {
 		return err
 	}
 
 	// Jadwalkan pembatalan otomatis jika tidak dibayar dalam sekian waktu
-	err = uc.Scheduler.ScheduleTaskCancellation(resp.ID, req.ExpiresAt)
+	uc.schedule(resp.ID, req.ExpiresAt.Time)
+
+	return nil
+}
+
+func (uc *taskUseCase) FinishTask(req *dto.FinishtTaskReqDTO) error {
+	// user_id wajib sejak ada kolaborator. Pesan tanpa user_id dari producer lama hanya diterima
+	// (tanpa pengecekan akses) jika AllowAnonymousFinish diaktifkan
+	anonymous := uc.Conf.AllowAnonymousFinish && req.ID != 0 && req.UserID == 0
+	if err := req.Validate(); err != nil && !anonymous {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if anonymous {
+		log.Printf("Task ID %d diselesaikan tanpa user_id karena AllowAnonymousFinish aktif", task.ID)
+	} else if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	// Task expired atau done tidak boleh diselesaikan lagi, jika tidak riwayat finished tercatat ulang
+	// dan occurrence berikutnya dari series dibuat dua kali
+	if task.Status != "pending" {
+		return infra_errors.NewError(infra_errors.TASK_NOT_PENDING, errors.New("task sudah tidak pending"))
+	}
+
+	if err := uc.checkBlockers(task.ID); err != nil {
+		return err
+	}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.FinishTask(req); err != nil {
+			return err
+		}
+
+		if err := r.CloseSessions(task.ID, uc.Clock.Now()); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyFinished, req.UserID, req.Meta, fields{"status": task.Status}, fields{"status": "done"})
+	})
+
+	// Task berubah status di antara GetTask dan update, misalnya di-expire worker scheduler
+	if err == repo.ErrTaskNotPending {
+		return infra_errors.NewError(infra_errors.TASK_NOT_PENDING, err)
+	}
+
 	if err != nil {
-		log.Println("Gagal menjadwalkan pembatalan task:", err)
+		return err
 	}
 
+	uc.cancelSchedule(req.ID)
+	uc.scheduleNextOccurrence(req.ID, req.Meta)
+	uc.rollUp(task.ParentID, req.Meta)
+
 	return nil
 }
 
-func (uc *taskUseCase) FinishTask(req *dto.FinishtTaskReqDTO) error {
+// DeleteTask menghapus task beserta jadwalnya, subtask pending mengikuti kebijakan ChildPolicy
+func (uc *taskUseCase) DeleteTask(req *dto.DeleteTaskReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeOwner(task, req.UserID); err != nil {
+		return err
+	}
+
+	return uc.deleteTask(task, "", req.UserID, req.Meta)
+}
+
+// expireTask meng-expire satu task lalu memproses subtask dan occurrence berikutnya.
+// reason diisi jika expiry terjadi otomatis karena parent, sehingga event-nya dikirim
+func (uc *taskUseCase) expireTask(taskID int64, reason string, meta dto.EventMetaDTO) error {
+	err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.ExpireTask(&dto.ExpireTaskReqDTO{ID: taskID}); err != nil {
+			return err
+		}
+
+		if err := r.CloseSessions(taskID, uc.Clock.Now()); err != nil {
+			return err
+		}
+
+		return record(r, taskID, historyExpired, 0, meta, fields{"status": "pending"}, fields{"status": "expired", "reason": reason})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if reason != "" {
+		uc.cancelSchedule(taskID)
+		uc.notifyTransition(taskConst.TASK_EXPIRED_EVENT, reason, taskID)
+	}
+
+	uc.handleChildren(taskID, false, meta)
+	uc.flagDependents(taskID, meta)
+	uc.scheduleNextOccurrence(taskID, meta)
+
+	return nil
+}
+
+// deleteTask menghapus task setelah subtask pending-nya diproses. Riwayat task tetap disimpan
+func (uc *taskUseCase) deleteTask(task *dto.TaskDTO, reason string, actorID int64, meta dto.EventMetaDTO) error {
+	uc.handleChildren(task.ID, true, meta)
+
+	err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.DeleteTask(task.ID); err != nil {
+			return err
+		}
+
+		if err := r.CloseSessions(task.ID, uc.Clock.Now()); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyDeleted, actorID, meta, fields{"status": task.Status, "title": task.Title}, fields{"reason": reason})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	uc.cancelSchedule(task.ID)
+
+	if reason != "" {
+		uc.publishTaskEvent(taskConst.TASK_DELETED_EVENT, reason, task)
+	}
+
+	return nil
+}
+
+// UpdateSeries mengubah aturan task berulang. Occurrence yang sudah dibuat tidak berubah,
+// aturan baru dipakai saat occurrence berikutnya dibuat
+func (uc *taskUseCase) UpdateSeries(req *dto.UpdateSeriesReqDTO) error {
+	series, err := uc.Repo.GetSeries(req.ID)
+	if err == repo.ErrSeriesNotFound {
+		return infra_errors.NewError(infra_errors.SERIES_NOT_FOUND, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if series.UserID != req.UserID {
+		return forbidden()
+	}
+
+	current := *series
+	if req.Title != "" {
+		series.Title = req.Title
+	}
+
+	// Aturan atau timezone baru dihitung ulang mulai hari ini
+	if (req.RRule != "" && req.RRule != series.RRule) || (req.Timezone != "" && req.Timezone != series.Timezone) {
+		if req.RRule != "" {
+			series.RRule = req.RRule
+		}
+		if req.Timezone != "" {
+			series.Timezone = req.Timezone
+		}
+
+		series.DTStart, err = seriesStart(time.Time{}, series.Timezone, uc.Clock.Now())
+		if err != nil {
+			return infra_errors.NewError(infra_errors.DATA_INVALID, err)
+		}
+
+		if _, err := buildRule(series.RRule, series.Timezone, series.DTStart); err != nil {
+			return infra_errors.NewError(infra_errors.DATA_INVALID, err)
+		}
+	}
+
+	old := fields{"title": current.Title, "rrule": current.RRule, "timezone": current.Timezone}
+	new := fields{"title": series.Title, "rrule": series.RRule, "timezone": series.Timezone}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.UpdateSeries(series); err != nil {
+			return err
+		}
+
+		return recordSeries(r, series.ID, historySeriesUpdated, req.UserID, req.Meta, old, new)
+	})
+	if err == repo.ErrSeriesNotFound {
+		return infra_errors.NewError(infra_errors.SERIES_NOT_FOUND, err)
+	}
+
+	return err
+}
 
-	err := uc.Repo.FinishTask(req)
+// StopSeries menghentikan pembuatan occurrence baru untuk task berulang
+func (uc *taskUseCase) StopSeries(req *dto.StopSeriesReqDTO) error {
+	series, err := uc.Repo.GetSeries(req.ID)
+	if err == repo.ErrSeriesNotFound {
+		return infra_errors.NewError(infra_errors.SERIES_NOT_FOUND, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if series.UserID != req.UserID {
+		return forbidden()
+	}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.StopSeries(series.ID); err != nil {
+			return err
+		}
+
+		return recordSeries(r, series.ID, historySeriesStopped, req.UserID, req.Meta, fields{"active": true}, fields{"active": false})
+	})
+	if err == repo.ErrSeriesNotFound {
+		return infra_errors.NewError(infra_errors.SERIES_NOT_FOUND, err)
+	}
+
+	return err
+}
+
+// addRecurringTask menyimpan series baru lalu membuat occurrence pertamanya.
+// req.Timezone sudah terisi dari AddTask
+func (uc *taskUseCase) addRecurringTask(req *dto.CreateTaskReqDTO) error {
+	now := uc.Clock.Now()
+	start, err := seriesStart(req.ExpiresAt.Time, req.Timezone, now)
+	if err != nil {
+		return infra_errors.NewError(infra_errors.DATA_INVALID, err)
+	}
+
+	series := &dto.TaskSeriesDTO{
+		UserID:      req.UserID,
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    req.Priority,
+		Tags:        req.Tags,
+		RRule:       req.RRule,
+		Timezone:    req.Timezone,
+		DTStart:     start,
+		Active:      true,
+
+		ExpiryPolicy: req.ExpiryPolicy,
+	}
+
+	first, err := nextOccurrence(series, now)
+	if err != nil {
+		return infra_errors.NewError(infra_errors.DATA_INVALID, err)
+	}
+
+	if first.IsZero() {
+		return infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("rrule tidak memiliki occurrence di masa depan"))
+	}
+
+	// Pengecekan kuota, series dan occurrence pertama berada dalam satu transaksi agar advisory lock
+	// kuota tetap dipegang sampai occurrence pertama tersimpan
+	var taskID int64
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := uc.checkQuota(r, req.UserID); err != nil {
+			return err
+		}
+
+		if series.ID, err = r.AddSeries(series); err != nil {
+			return err
+		}
+
+		taskID, err = insertOccurrence(r, series, first, req.UserID, req.Meta)
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	uc.schedule(taskID, first)
+
+	return nil
+}
+
+// addOccurrence membuat baris task untuk satu occurrence lalu menjadwalkan expiry-nya
+func (uc *taskUseCase) addOccurrence(series *dto.TaskSeriesDTO, expiresAt time.Time, actorID int64, meta dto.EventMetaDTO) error {
+	var taskID int64
+	err := uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		var err error
+		taskID, err = insertOccurrence(r, series, expiresAt, actorID, meta)
+		return err
+	})
+
+	if err == repo.ErrOccurrenceExists {
+		return nil
+	}
 
 	if err != nil {
 		return err
 	}
 
+	uc.schedule(taskID, expiresAt)
+
 	return nil
 }
+
+// insertOccurrence menyimpan occurrence beserta tag dan riwayat pembuatannya di dalam transaksi r
+func insertOccurrence(r repo.TaskRepository, series *dto.TaskSeriesDTO, expiresAt time.Time, actorID int64, meta dto.EventMetaDTO) (int64, error) {
+	resp, err := r.AddOccurrence(series, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.AddTags(resp.ID, series.Tags); err != nil {
+		return 0, err
+	}
+
+	return resp.ID, record(r, resp.ID, historyCreated, actorID, meta, nil, fields{
+		"title":         series.Title,
+		"priority":      series.Priority,
+		"tags":          series.Tags,
+		"expires_at":    expiresAt,
+		"series_id":     series.ID,
+		"expiry_policy": series.ExpiryPolicy,
+	})
+}
+
+// scheduleNextOccurrence membuat occurrence berikutnya jika task adalah bagian dari series aktif.
+// Occurrence yang terlewat (misalnya consumer sempat mati) dilewati, dihitung dari sekarang
+func (uc *taskUseCase) scheduleNextOccurrence(taskID int64, meta dto.EventMetaDTO) {
+	task, err := uc.Repo.GetTask(taskID)
+	if err != nil {
+		log.Println("Gagal mengambil task untuk occurrence berikutnya:", err)
+		return
+	}
+
+	if task.SeriesID == nil {
+		return
+	}
+
+	series, err := uc.Repo.GetSeries(*task.SeriesID)
+	if err != nil {
+		log.Println("Gagal mengambil task series:", err)
+		return
+	}
+
+	if !series.Active {
+		return
+	}
+
+	after := task.ExpiresAt
+	if now := uc.Clock.Now(); after.Before(now) {
+		after = now
+	}
+
+	next, err := nextOccurrence(series, after)
+	if err != nil {
+		log.Println("Gagal menghitung occurrence berikutnya:", err)
+		return
+	}
+
+	if next.IsZero() {
+		log.Printf("Task series ID %d sudah tidak memiliki occurrence berikutnya", series.ID)
+		return
+	}
+
+	if err := uc.addOccurrence(series, next, 0, meta); err != nil {
+		log.Println("Gagal membuat occurrence berikutnya:", err)
+	}
+}
+
+// SnoozeTask memundurkan deadline task pending, baik relatif (Duration) maupun absolut (Until)
+func (uc *taskUseCase) SnoozeTask(req *dto.SnoozeTaskReqDTO) error {
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	if task.Status != "pending" {
+		return infra_errors.NewError(infra_errors.TASK_NOT_PENDING, errors.New("task sudah tidak pending"))
+	}
+
+	if uc.Conf.MaxSnooze > 0 && task.SnoozeCount >= uc.Conf.MaxSnooze {
+		return infra_errors.NewError(infra_errors.SNOOZE_LIMIT_REACHED, errors.New("batas snooze task sudah tercapai"))
+	}
+
+	var until *time.Time
+	if req.Until != nil {
+		loc, err := uc.userLocation(req.UserID, req.Timezone)
+		if err != nil {
+			return err
+		}
+
+		resolved := resolveTime(*req.Until, loc)
+		until = &resolved
+	}
+
+	expiresAt, err := snoozeUntil(task.ExpiresAt, req.Duration, until, uc.Clock.Now())
+	if err != nil {
+		return infra_errors.NewError(infra_errors.DATA_INVALID, err)
+	}
+
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.SnoozeTask(task.ID, expiresAt, uc.Conf.MaxSnooze); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historySnoozed, req.UserID, req.Meta,
+			fields{"expires_at": task.ExpiresAt, "snooze_count": task.SnoozeCount},
+			fields{"expires_at": expiresAt, "snooze_count": task.SnoozeCount + 1})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	uc.reschedule(task.ID, expiresAt)
+
+	return nil
+}
+
+// getTask mengambil task dan mengubah ErrTaskNotFound menjadi error TASK_NOT_FOUND
+func (uc *taskUseCase) getTask(id int64) (*dto.TaskDTO, error) {
+	task, err := uc.Repo.GetTask(id)
+	if err == repo.ErrTaskNotFound {
+		return nil, infra_errors.NewError(infra_errors.TASK_NOT_FOUND, err)
+	}
+
+	return task, err
+}
+
+// validationError membungkus error ozzo-validation menjadi DATA_INVALID beserta detail per field
+func validationError(err error) error {
+	cerr := infra_errors.NewError(infra_errors.DATA_INVALID, err)
+	cerr.SetValidationMessage(err)
+	return cerr
+}
+
+// snoozeUntil menghitung deadline baru. Snooze relatif dihitung dari deadline saat ini,
+// atau dari sekarang jika deadline tersebut sudah lewat
+func snoozeUntil(current time.Time, duration string, until *time.Time, now time.Time) (time.Time, error) {
+	if until != nil && duration != "" {
+		return time.Time{}, errors.New("isi salah satu dari duration atau until")
+	}
+
+	if until != nil {
+		if !until.After(current) || !until.After(now) {
+			return time.Time{}, errors.New("until harus setelah deadline saat ini")
+		}
+		return *until, nil
+	}
+
+	d, err := parseDuration(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if current.Before(now) {
+		current = now
+	}
+
+	return current.Add(d), nil
+}
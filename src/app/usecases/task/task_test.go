@@ -0,0 +1,189 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTaskTransaction(t *testing.T) {
+	expiresAt := time.Date(2025, 3, 13, 10, 0, 0, 0, time.UTC)
+	newReq := func() *dto.CreateTaskReqDTO {
+		return &dto.CreateTaskReqDTO{UserID: 7, Title: "Laporan", Tags: []string{"kantor"}, ExpiresAt: dto.Timestamp{Time: expiresAt}}
+	}
+
+	t.Run("task, tag dan riwayat tersimpan bersama", func(t *testing.T) {
+		r := newFakeRepo()
+		uc, s, _, _ := newTestUseCase(r, config.TaskConf{})
+
+		assert.NoError(t, uc.AddTask(newReq()))
+		assert.Len(t, r.tasks, 1)
+		assert.Equal(t, []string{historyCreated}, r.events(1))
+		assert.Equal(t, expiresAt, s.expiries[1])
+	})
+
+	t.Run("gagal menyimpan tag membatalkan task", func(t *testing.T) {
+		r := newFakeRepo()
+		r.errAddTags = errors.New("tag gagal")
+		uc, s, _, _ := newTestUseCase(r, config.TaskConf{})
+
+		assert.Error(t, uc.AddTask(newReq()))
+		assert.Empty(t, r.tasks)
+		assert.Empty(t, r.history)
+		assert.Empty(t, s.expiries)
+	})
+}
+
+func TestAddRecurringTaskTransaction(t *testing.T) {
+	newReq := func() *dto.CreateTaskReqDTO {
+		return &dto.CreateTaskReqDTO{UserID: 7, Title: "Olahraga", Tags: []string{"rutin"}, RRule: "FREQ=DAILY", Timezone: "UTC"}
+	}
+
+	t.Run("series, occurrence pertama dan riwayat tersimpan bersama", func(t *testing.T) {
+		r := newFakeRepo()
+		uc, s, _, _ := newTestUseCase(r, config.TaskConf{MaxPending: 1})
+
+		assert.NoError(t, uc.AddTask(newReq()))
+		assert.Len(t, r.series, 1)
+		if assert.Len(t, r.tasks, 1) {
+			assert.Equal(t, int64(1), *r.tasks[1].SeriesID)
+			assert.Equal(t, []string{historyCreated}, r.events(1))
+			assert.Equal(t, r.tasks[1].ExpiresAt, s.expiries[1])
+		}
+
+		// Occurrence pertama sudah dihitung kuota sehingga series kedua ditolak
+		assert.Equal(t, infra_errors.TASK_QUOTA_EXCEEDED, errorCode(uc.AddTask(newReq())))
+		assert.Len(t, r.series, 1)
+	})
+
+	t.Run("gagal menyimpan occurrence membatalkan series", func(t *testing.T) {
+		r := newFakeRepo()
+		r.errAddTags = errors.New("tag gagal")
+		uc, s, _, _ := newTestUseCase(r, config.TaskConf{})
+
+		assert.Error(t, uc.AddTask(newReq()))
+		assert.Empty(t, r.series)
+		assert.Empty(t, r.tasks)
+		assert.Empty(t, r.history)
+		assert.Empty(t, s.expiries)
+	})
+}
+
+func TestRecurringTaskCopiesExpiryPolicy(t *testing.T) {
+	r := newFakeRepo()
+	uc, _, _, _ := newTestUseCase(r, config.TaskConf{})
+
+	assert.NoError(t, uc.AddTask(&dto.CreateTaskReqDTO{UserID: 7, Title: "Olahraga", RRule: "FREQ=DAILY", Timezone: "UTC",
+		ExpiryPolicy: taskConst.EXPIRY_POLICY_GRACE}))
+	assert.Equal(t, taskConst.EXPIRY_POLICY_GRACE, r.series[1].ExpiryPolicy)
+	assert.Equal(t, taskConst.EXPIRY_POLICY_GRACE, r.tasks[1].ExpiryPolicy)
+
+	// Occurrence berikutnya dibuat dari series yang dibaca ulang dari repository
+	assert.NoError(t, uc.FinishTask(&dto.FinishtTaskReqDTO{ID: 1, UserID: 7}))
+	if assert.Len(t, r.tasks, 2) {
+		assert.Equal(t, taskConst.EXPIRY_POLICY_GRACE, r.tasks[2].ExpiryPolicy)
+		assert.True(t, r.tasks[2].ExpiresAt.After(r.tasks[1].ExpiresAt))
+	}
+}
+
+func TestSnoozeTask(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	conf := config.TaskConf{MaxSnooze: 2}
+
+	t.Run("snooze relatif dihitung dari deadline saat ini", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour)})
+		uc, s, _, _ := newTestUseCase(r, conf)
+
+		assert.NoError(t, uc.SnoozeTask(&dto.SnoozeTaskReqDTO{ID: 1, UserID: 7, Duration: "30m"}))
+		assert.Equal(t, now.Add(90*time.Minute), r.tasks[1].ExpiresAt)
+		assert.Equal(t, now.Add(90*time.Minute), s.expiries[1])
+		assert.Equal(t, 1, r.tasks[1].SnoozeCount)
+		assert.Equal(t, []string{historySnoozed}, r.events(1))
+	})
+
+	t.Run("snooze relatif dihitung dari sekarang jika deadline sudah lewat", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour)})
+		uc, s, _, c := newTestUseCase(r, conf)
+
+		c.Advance(3 * time.Hour)
+		assert.NoError(t, uc.SnoozeTask(&dto.SnoozeTaskReqDTO{ID: 1, UserID: 7, Duration: "1h"}))
+		assert.Equal(t, c.Now().Add(time.Hour), r.tasks[1].ExpiresAt)
+		assert.Equal(t, c.Now().Add(time.Hour), s.expiries[1])
+	})
+
+	t.Run("batas snooze", func(t *testing.T) {
+		r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(time.Hour), SnoozeCount: 2})
+		uc, s, _, _ := newTestUseCase(r, conf)
+
+		err := uc.SnoozeTask(&dto.SnoozeTaskReqDTO{ID: 1, UserID: 7, Duration: "1h"})
+		assert.Equal(t, infra_errors.SNOOZE_LIMIT_REACHED, errorCode(err))
+		assert.Equal(t, now.Add(time.Hour), r.tasks[1].ExpiresAt)
+		assert.Empty(t, s.expiries)
+	})
+}
+
+// staleRepo mengembalikan task pending dari GetTask walaupun task sudah berubah status, seperti task yang
+// di-expire worker scheduler di antara GetTask dan update
+type staleRepo struct {
+	*fakeRepo
+}
+
+func (s staleRepo) GetTask(id int64) (*dto.TaskDTO, error) {
+	task, err := s.fakeRepo.GetTask(id)
+	if err == nil {
+		task.Status = "pending"
+	}
+
+	return task, err
+}
+
+func TestFinishTask(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	series := dto.TaskSeriesDTO{ID: 1, UserID: 7, RRule: "FREQ=DAILY", Timezone: "UTC", DTStart: now.Add(-48 * time.Hour), Active: true}
+
+	tests := []struct {
+		name   string
+		status string
+		stale  bool
+		code   infra_errors.ErrorCode
+	}{
+		{"task pending diselesaikan", "pending", false, 0},
+		{"task expired ditolak", "expired", false, infra_errors.TASK_NOT_PENDING},
+		{"task done ditolak", "done", false, infra_errors.TASK_NOT_PENDING},
+		{"task expired di tengah request ditolak", "expired", true, infra_errors.TASK_NOT_PENDING},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, ExpiresAt: now.Add(-time.Hour), Status: tt.status, SeriesID: int64Ptr(1)})
+			copied := series
+			r.series[1] = &copied
+			uc, _, _, _ := newTestUseCase(r, config.TaskConf{})
+			if tt.stale {
+				uc.Repo = staleRepo{r}
+			}
+
+			err := uc.FinishTask(&dto.FinishtTaskReqDTO{ID: 1, UserID: 7})
+			assert.Equal(t, tt.code, errorCode(err))
+
+			if tt.code != 0 {
+				// Status, riwayat dan series tidak berubah, occurrence berikutnya tidak dibuat
+				assert.Equal(t, tt.status, r.tasks[1].Status)
+				assert.Empty(t, r.events(1))
+				assert.Len(t, r.tasks, 1)
+				return
+			}
+
+			assert.Equal(t, "done", r.tasks[1].Status)
+			assert.Equal(t, []string{historyFinished}, r.events(1))
+			assert.Len(t, r.tasks, 2)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	repo "todo_list_consumer/src/app/repositories/task"
+)
+
+// StartTimer membuka sesi kerja user pada task pending. Satu user hanya boleh punya satu sesi terbuka per task
+func (uc *taskUseCase) StartTimer(req *dto.TimerReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.authorize(task, req.UserID); err != nil {
+		return err
+	}
+
+	if task.Status != "pending" {
+		return infra_errors.NewError(infra_errors.TASK_NOT_PENDING, errors.New("task sudah tidak pending"))
+	}
+
+	now := uc.Clock.Now()
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.StartSession(task.ID, req.UserID, now); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyTimerStarted, req.UserID, req.Meta, nil, fields{"started_at": now})
+	})
+
+	if err == repo.ErrSessionOpen {
+		return infra_errors.NewError(infra_errors.TIMER_ALREADY_RUNNING, err)
+	}
+
+	return err
+}
+
+// StopTimer menutup sesi kerja user yang masih terbuka pada task
+func (uc *taskUseCase) StopTimer(req *dto.TimerReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	task, err := uc.getTask(req.ID)
+	if err != nil {
+		return err
+	}
+
+	now := uc.Clock.Now()
+	err = uc.Repo.WithTransaction(func(r repo.TaskRepository) error {
+		if err := r.StopSession(task.ID, req.UserID, now); err != nil {
+			return err
+		}
+
+		return record(r, task.ID, historyTimerStopped, req.UserID, req.Meta, nil, fields{"ended_at": now})
+	})
+
+	if err == repo.ErrSessionNotOpen {
+		return infra_errors.NewError(infra_errors.TIMER_NOT_RUNNING, err)
+	}
+
+	return err
+}
+
+// GetTimeReport mengembalikan total waktu kerja user per hari dari From sampai To (inklusif),
+// dihitung di timezone user
+func (uc *taskUseCase) GetTimeReport(req *dto.TimeReportReqDTO) ([]dto.TimeReportDTO, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	// Laporan waktu kerja hanya boleh dibaca user yang bersangkutan
+	if req.RequesterID != req.UserID {
+		return nil, forbidden()
+	}
+
+	loc, err := uc.userLocation(req.UserID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	from, _ := time.ParseInLocation("2006-01-02", req.From, loc)
+	to, _ := time.ParseInLocation("2006-01-02", req.To, loc)
+	if to.Before(from) {
+		return nil, infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("to harus sama atau setelah from"))
+	}
+
+	return uc.Repo.GetTimeReport(req.UserID, loc.String(), from, to.AddDate(0, 0, 1))
+}
@@ -0,0 +1,130 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartTimer(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		userID int64
+		open   bool // user sudah punya sesi terbuka pada task
+		code   infra_errors.ErrorCode
+	}{
+		{"owner", "pending", 7, false, 0},
+		{"kolaborator view", "pending", 9, false, infra_errors.TASK_FORBIDDEN},
+		{"user lain", "pending", 8, false, infra_errors.TASK_FORBIDDEN},
+		{"task sudah done", "done", 7, false, infra_errors.TASK_NOT_PENDING},
+		{"sesi masih terbuka", "pending", 7, true, infra_errors.TIMER_ALREADY_RUNNING},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7, Status: tt.status})
+			r.members[1] = []dto.CollaboratorDTO{{UserID: 9, Permission: taskConst.PERMISSION_VIEW}}
+			uc, _, _, c := newTestUseCase(r, config.TaskConf{})
+			if tt.open {
+				r.sessions = []fakeSession{{taskID: 1, userID: 7, startedAt: c.Now().Add(-time.Hour)}}
+			}
+
+			err := uc.StartTimer(&dto.TimerReqDTO{ID: 1, UserID: tt.userID})
+			assert.Equal(t, tt.code, errorCode(err))
+			if tt.code != 0 {
+				assert.Empty(t, r.history)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{historyTimerStarted}, r.events(1))
+			assert.Equal(t, []fakeSession{{taskID: 1, userID: 7, startedAt: c.Now()}}, r.sessions)
+		})
+	}
+}
+
+func TestStopTimer(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  int64
+		seconds int64
+		code    infra_errors.ErrorCode
+	}{
+		{"sesi milik user", 7, 1800, 0},
+		{"user tanpa sesi terbuka", 8, 0, infra_errors.TIMER_NOT_RUNNING},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo(dto.TaskDTO{ID: 1, UserID: 7})
+			uc, _, _, c := newTestUseCase(r, config.TaskConf{})
+			r.sessions = []fakeSession{{taskID: 1, userID: 7, startedAt: c.Now()}}
+			c.Advance(30 * time.Minute)
+
+			err := uc.StopTimer(&dto.TimerReqDTO{ID: 1, UserID: tt.userID})
+			assert.Equal(t, tt.code, errorCode(err))
+
+			// Sesi yang gagal ditutup tetap terbuka dan terus dihitung
+			c.Advance(time.Hour)
+			task, _ := r.GetTask(1)
+			if tt.code == 0 {
+				assert.Equal(t, []string{historyTimerStopped}, r.events(1))
+				assert.Equal(t, tt.seconds, task.TrackedSeconds)
+			} else {
+				assert.Empty(t, r.history)
+				assert.Equal(t, int64(5400), task.TrackedSeconds)
+			}
+		})
+	}
+}
+
+func TestGetTimeReport(t *testing.T) {
+	// 2025-03-01 23:30 UTC sudah 2025-03-02 di Asia/Jakarta
+	late := time.Date(2025, 3, 1, 23, 30, 0, 0, time.UTC)
+	sessions := []fakeSession{
+		{taskID: 1, userID: 7, startedAt: late, endedAt: timePtr(late.Add(time.Hour))},
+		{taskID: 2, userID: 7, startedAt: late.Add(-12 * time.Hour), endedAt: timePtr(late.Add(-11 * time.Hour))},
+		{taskID: 1, userID: 8, startedAt: late, endedAt: timePtr(late.Add(time.Hour))},
+	}
+
+	tests := []struct {
+		name     string
+		req      dto.TimeReportReqDTO
+		timezone string
+		report   []dto.TimeReportDTO
+		code     infra_errors.ErrorCode
+	}{
+		{"hari dihitung di timezone user", dto.TimeReportReqDTO{UserID: 7, RequesterID: 7, From: "2025-03-01", To: "2025-03-02"}, "Asia/Jakarta",
+			[]dto.TimeReportDTO{{Day: "2025-03-01", Seconds: 3600}, {Day: "2025-03-02", Seconds: 3600}}, 0},
+		{"hari dihitung di UTC", dto.TimeReportReqDTO{UserID: 7, RequesterID: 7, From: "2025-03-01", To: "2025-03-01"}, "UTC",
+			[]dto.TimeReportDTO{{Day: "2025-03-01", Seconds: 7200}}, 0},
+		{"rentang tanpa sesi", dto.TimeReportReqDTO{UserID: 7, RequesterID: 7, From: "2025-03-05", To: "2025-03-06"}, "UTC",
+			[]dto.TimeReportDTO{}, 0},
+		{"laporan user lain", dto.TimeReportReqDTO{UserID: 7, RequesterID: 8, From: "2025-03-01", To: "2025-03-02"}, "UTC",
+			nil, infra_errors.TASK_FORBIDDEN},
+		{"tanpa requester", dto.TimeReportReqDTO{UserID: 7, From: "2025-03-01", To: "2025-03-02"}, "UTC",
+			nil, infra_errors.DATA_INVALID},
+		{"to sebelum from", dto.TimeReportReqDTO{UserID: 7, RequesterID: 7, From: "2025-03-02", To: "2025-03-01"}, "UTC",
+			nil, infra_errors.DATA_INVALID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeRepo()
+			r.sessions = sessions
+			r.timezones[7] = tt.timezone
+			uc, _, _, _ := newTestUseCase(r, config.TaskConf{DefaultTimezone: "UTC"})
+
+			report, err := uc.GetTimeReport(&tt.req)
+			assert.Equal(t, tt.code, errorCode(err))
+			assert.Equal(t, tt.report, report)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package task
+
+import (
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	infra_errors "todo_list_consumer/src/infra/errors"
+)
+
+// SetTimezone menyimpan timezone default user untuk waktu tanpa offset dan task berulang
+func (uc *taskUseCase) SetTimezone(req *dto.SetTimezoneReqDTO) error {
+	if err := req.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return infra_errors.NewError(infra_errors.DATA_INVALID, err)
+	}
+
+	return uc.Repo.SetUserTimezone(req.UserID, req.Timezone)
+}
+
+// userLocation menentukan zona waktu: timezone dari pesan, lalu timezone user, lalu default config
+func (uc *taskUseCase) userLocation(userID int64, timezone string) (*time.Location, error) {
+	if timezone == "" {
+		userTimezone, err := uc.Repo.GetUserTimezone(userID)
+		if err != nil {
+			return nil, err
+		}
+		timezone = userTimezone
+	}
+
+	if timezone == "" {
+		timezone = uc.Conf.DefaultTimezone
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, infra_errors.NewError(infra_errors.DATA_INVALID, err)
+	}
+
+	return loc, nil
+}
+
+// resolveTime mengubah Timestamp menjadi waktu absolut. Waktu dengan offset dipakai apa adanya,
+// waktu tanpa offset dibaca sebagai jam dinding di loc
+func resolveTime(ts dto.Timestamp, loc *time.Location) time.Time {
+	if ts.IsZero() || !ts.Floating {
+		return ts.Time
+	}
+
+	return wallClock(ts.Time, loc)
+}
+
+// wallClock membentuk waktu dengan jam dinding t di zona loc. Saat DST, jam yang tidak ada
+// (loncatan maju) digeser maju sebesar loncatannya, dan jam yang muncul dua kali (mundur)
+// memakai kemunculan pertama
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+
+	// Offset sebelum dan sesudah kemungkinan transisi DST di sekitar jam tersebut
+	_, before := wall.Add(-12 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(12 * time.Hour).In(loc).Zone()
+
+	first := wall.Add(-time.Duration(before) * time.Second)
+	second := wall.Add(-time.Duration(after) * time.Second)
+
+	switch {
+	case sameWallClock(first, wall, loc) && sameWallClock(second, wall, loc):
+		if second.Before(first) {
+			return second.In(loc)
+		}
+		return first.In(loc)
+	case sameWallClock(first, wall, loc):
+		return first.In(loc)
+	case sameWallClock(second, wall, loc):
+		return second.In(loc)
+	default:
+		return first.In(loc)
+	}
+}
+
+func sameWallClock(t time.Time, wall time.Time, loc *time.Location) bool {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC).Equal(wall)
+}
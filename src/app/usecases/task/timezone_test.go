@@ -0,0 +1,64 @@
+package task
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTimeKeepsOffset(t *testing.T) {
+	req := dto.CreateTaskReqDTO{}
+	err := json.Unmarshal([]byte(`{"expires_at": "2025-06-01T09:00:00+02:00"}`), &req)
+	assert.NoError(t, err)
+	assert.False(t, req.ExpiresAt.Floating)
+
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	// Offset dari producer tidak boleh ditimpa zona user
+	assert.Equal(t, time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC), resolveTime(req.ExpiresAt, loc).UTC())
+}
+
+func TestResolveTimeFloatingUsesLocation(t *testing.T) {
+	req := dto.CreateTaskReqDTO{}
+	err := json.Unmarshal([]byte(`{"expires_at": "2025-06-01T09:00:00"}`), &req)
+	assert.NoError(t, err)
+	assert.True(t, req.ExpiresAt.Floating)
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	// 09:00 CEST = 07:00 UTC
+	assert.Equal(t, time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC), resolveTime(req.ExpiresAt, loc).UTC())
+}
+
+func TestTimestampRejectsUnknownFormat(t *testing.T) {
+	req := dto.CreateTaskReqDTO{}
+	assert.Error(t, json.Unmarshal([]byte(`{"expires_at": "besok"}`), &req))
+}
+
+func TestWallClockDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name string
+		wall time.Time
+		want time.Time
+	}{
+		// 2025-03-09 02:00 EST loncat ke 03:00 EDT, 02:30 tidak ada sehingga digeser ke 03:30 EDT
+		{"loncatan maju", time.Date(2025, 3, 9, 2, 30, 0, 0, time.UTC), time.Date(2025, 3, 9, 7, 30, 0, 0, time.UTC)},
+		// 2025-11-02 01:30 muncul dua kali, yang dipakai 01:30 EDT (kemunculan pertama)
+		{"jam ganda", time.Date(2025, 11, 2, 1, 30, 0, 0, time.UTC), time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC)},
+		{"sebelum transisi", time.Date(2025, 3, 9, 1, 59, 0, 0, time.UTC), time.Date(2025, 3, 9, 6, 59, 0, 0, time.UTC)},
+		{"setelah transisi", time.Date(2025, 11, 2, 2, 0, 0, 0, time.UTC), time.Date(2025, 11, 2, 7, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, wallClock(tt.wall, loc).UTC())
+		})
+	}
+}
@@ -13,6 +13,9 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
+// messageHandler memproses payload sebuah subject. meta berisi subject dan message ID untuk riwayat task
+type messageHandler func(data []byte, meta dto.EventMetaDTO)
+
 // Interface untuk inisialisasi NATS
 type NotifTaskInterface interface {
 	InitNats()
@@ -20,10 +23,10 @@ type NotifTaskInterface interface {
 
 // Struct untuk worker yang menangani task dari NATS
 type TaskWorkerImpl struct {
-	nats     *natsBroker.Nats        // Instance NATS connection
-	subjects map[string]func([]byte) // Mapping subject ke handler-nya
-	queues   string                  // Nama queue
-	UseCase  useCase.TaskUseCase     // Use case untuk task
+	nats     *natsBroker.Nats          // Instance NATS connection
+	subjects map[string]messageHandler // Mapping subject ke handler-nya
+	queues   string                    // Nama queue
+	UseCase  useCase.TaskUseCase       // Use case untuk task
 }
 
 // Konstruktor untuk membuat TaskWorker
@@ -32,29 +35,186 @@ func NewTaskWorker(Nats *natsBroker.Nats, useCase useCase.TaskUseCase) NotifTask
 		nats:    Nats,
 		queues:  taskConst.TASK_QUEUE,
 		UseCase: useCase,
-		subjects: map[string]func([]byte){
+		subjects: map[string]messageHandler{
 			// Handler untuk subject ADD_TASK
-			taskConst.ADD_TASK: func(data []byte) {
+			taskConst.ADD_TASK: func(data []byte, meta dto.EventMetaDTO) {
 				taskDTO := dto.CreateTaskReqDTO{}
 				if err := json.Unmarshal(data, &taskDTO); err != nil {
 					log.Printf("Error parsing ADDTASK payload: %+v", err)
 					return
 				}
+				taskDTO.Meta = meta
 				if err := useCase.AddTask(&taskDTO); err != nil {
 					log.Printf("Error executing AddTask: %+v", err)
 				}
 			},
 			// Handler untuk subject FINISH_TASK
-			taskConst.FINISH_TASK: func(data []byte) {
+			taskConst.FINISH_TASK: func(data []byte, meta dto.EventMetaDTO) {
 				taskDTO := dto.FinishtTaskReqDTO{}
 				if err := json.Unmarshal(data, &taskDTO); err != nil {
 					log.Printf("Error parsing FINISH_TASK payload: %+v", err)
 					return
 				}
+				taskDTO.Meta = meta
 				if err := useCase.FinishTask(&taskDTO); err != nil {
 					log.Printf("Error executing FinishTask: %+v", err)
 				}
 			},
+			// Handler untuk subject SNOOZE_TASK
+			taskConst.SNOOZE_TASK: func(data []byte, meta dto.EventMetaDTO) {
+				taskDTO := dto.SnoozeTaskReqDTO{}
+				if err := json.Unmarshal(data, &taskDTO); err != nil {
+					log.Printf("Error parsing SNOOZE_TASK payload: %+v", err)
+					return
+				}
+				taskDTO.Meta = meta
+				if err := useCase.SnoozeTask(&taskDTO); err != nil {
+					log.Printf("Error executing SnoozeTask: %+v", err)
+				}
+			},
+			// Handler untuk subject UPDATE_SERIES
+			taskConst.UPDATE_SERIES: func(data []byte, meta dto.EventMetaDTO) {
+				seriesDTO := dto.UpdateSeriesReqDTO{}
+				if err := json.Unmarshal(data, &seriesDTO); err != nil {
+					log.Printf("Error parsing UPDATE_SERIES payload: %+v", err)
+					return
+				}
+				seriesDTO.Meta = meta
+				if err := useCase.UpdateSeries(&seriesDTO); err != nil {
+					log.Printf("Error executing UpdateSeries: %+v", err)
+				}
+			},
+			// Handler untuk subject STOP_SERIES
+			taskConst.STOP_SERIES: func(data []byte, meta dto.EventMetaDTO) {
+				seriesDTO := dto.StopSeriesReqDTO{}
+				if err := json.Unmarshal(data, &seriesDTO); err != nil {
+					log.Printf("Error parsing STOP_SERIES payload: %+v", err)
+					return
+				}
+				seriesDTO.Meta = meta
+				if err := useCase.StopSeries(&seriesDTO); err != nil {
+					log.Printf("Error executing StopSeries: %+v", err)
+				}
+			},
+			// Handler untuk subject DELETE_TASK
+			taskConst.DELETE_TASK: func(data []byte, meta dto.EventMetaDTO) {
+				taskDTO := dto.DeleteTaskReqDTO{}
+				if err := json.Unmarshal(data, &taskDTO); err != nil {
+					log.Printf("Error parsing DELETE_TASK payload: %+v", err)
+					return
+				}
+				taskDTO.Meta = meta
+				if err := useCase.DeleteTask(&taskDTO); err != nil {
+					log.Printf("Error executing DeleteTask: %+v", err)
+				}
+			},
+			// Handler untuk subject ADD_TAGS
+			taskConst.ADD_TAGS: func(data []byte, meta dto.EventMetaDTO) {
+				tagsDTO := dto.TagsReqDTO{}
+				if err := json.Unmarshal(data, &tagsDTO); err != nil {
+					log.Printf("Error parsing ADD_TAGS payload: %+v", err)
+					return
+				}
+				tagsDTO.Meta = meta
+				if err := useCase.AddTags(&tagsDTO); err != nil {
+					log.Printf("Error executing AddTags: %+v", err)
+				}
+			},
+			// Handler untuk subject REMOVE_TAGS
+			taskConst.REMOVE_TAGS: func(data []byte, meta dto.EventMetaDTO) {
+				tagsDTO := dto.TagsReqDTO{}
+				if err := json.Unmarshal(data, &tagsDTO); err != nil {
+					log.Printf("Error parsing REMOVE_TAGS payload: %+v", err)
+					return
+				}
+				tagsDTO.Meta = meta
+				if err := useCase.RemoveTags(&tagsDTO); err != nil {
+					log.Printf("Error executing RemoveTags: %+v", err)
+				}
+			},
+			// Handler untuk subject CHANGE_PRIORITY
+			taskConst.CHANGE_PRIORITY: func(data []byte, meta dto.EventMetaDTO) {
+				priorityDTO := dto.ChangePriorityReqDTO{}
+				if err := json.Unmarshal(data, &priorityDTO); err != nil {
+					log.Printf("Error parsing CHANGE_PRIORITY payload: %+v", err)
+					return
+				}
+				priorityDTO.Meta = meta
+				if err := useCase.ChangePriority(&priorityDTO); err != nil {
+					log.Printf("Error executing ChangePriority: %+v", err)
+				}
+			},
+			// Handler untuk subject ASSIGN_TASK
+			taskConst.ASSIGN_TASK: func(data []byte, meta dto.EventMetaDTO) {
+				assignDTO := dto.AssignTaskReqDTO{}
+				if err := json.Unmarshal(data, &assignDTO); err != nil {
+					log.Printf("Error parsing ASSIGN_TASK payload: %+v", err)
+					return
+				}
+				assignDTO.Meta = meta
+				if err := useCase.AssignTask(&assignDTO); err != nil {
+					log.Printf("Error executing AssignTask: %+v", err)
+				}
+			},
+			// Handler untuk subject ADD_DEPENDENCY
+			taskConst.ADD_DEPENDENCY: func(data []byte, meta dto.EventMetaDTO) {
+				dependencyDTO := dto.DependencyReqDTO{}
+				if err := json.Unmarshal(data, &dependencyDTO); err != nil {
+					log.Printf("Error parsing ADD_DEPENDENCY payload: %+v", err)
+					return
+				}
+				dependencyDTO.Meta = meta
+				if err := useCase.AddDependency(&dependencyDTO); err != nil {
+					log.Printf("Error executing AddDependency: %+v", err)
+				}
+			},
+			// Handler untuk subject REMOVE_DEPENDENCY
+			taskConst.REMOVE_DEPENDENCY: func(data []byte, meta dto.EventMetaDTO) {
+				dependencyDTO := dto.DependencyReqDTO{}
+				if err := json.Unmarshal(data, &dependencyDTO); err != nil {
+					log.Printf("Error parsing REMOVE_DEPENDENCY payload: %+v", err)
+					return
+				}
+				dependencyDTO.Meta = meta
+				if err := useCase.RemoveDependency(&dependencyDTO); err != nil {
+					log.Printf("Error executing RemoveDependency: %+v", err)
+				}
+			},
+			// Handler untuk subject SET_TIMEZONE
+			taskConst.SET_TIMEZONE: func(data []byte, meta dto.EventMetaDTO) {
+				timezoneDTO := dto.SetTimezoneReqDTO{}
+				if err := json.Unmarshal(data, &timezoneDTO); err != nil {
+					log.Printf("Error parsing SET_TIMEZONE payload: %+v", err)
+					return
+				}
+				if err := useCase.SetTimezone(&timezoneDTO); err != nil {
+					log.Printf("Error executing SetTimezone: %+v", err)
+				}
+			},
+			// Handler untuk subject START_TIMER
+			taskConst.START_TIMER: func(data []byte, meta dto.EventMetaDTO) {
+				timerDTO := dto.TimerReqDTO{}
+				if err := json.Unmarshal(data, &timerDTO); err != nil {
+					log.Printf("Error parsing START_TIMER payload: %+v", err)
+					return
+				}
+				timerDTO.Meta = meta
+				if err := useCase.StartTimer(&timerDTO); err != nil {
+					log.Printf("Error executing StartTimer: %+v", err)
+				}
+			},
+			// Handler untuk subject STOP_TIMER
+			taskConst.STOP_TIMER: func(data []byte, meta dto.EventMetaDTO) {
+				timerDTO := dto.TimerReqDTO{}
+				if err := json.Unmarshal(data, &timerDTO); err != nil {
+					log.Printf("Error parsing STOP_TIMER payload: %+v", err)
+					return
+				}
+				timerDTO.Meta = meta
+				if err := useCase.StopTimer(&timerDTO); err != nil {
+					log.Printf("Error executing StopTimer: %+v", err)
+				}
+			},
 		},
 	}
 
@@ -74,9 +234,14 @@ func (p *TaskWorkerImpl) InitNats() {
 }
 
 // Fungsi untuk menangani event dari NATS
-func eventNotificationWorker(t *TaskWorkerImpl, subject string, handler func([]byte)) {
+func eventNotificationWorker(t *TaskWorkerImpl, subject string, handler messageHandler) {
 	_, err := t.nats.Conn.QueueSubscribe(subject, t.queues, func(msg *nats.Msg) {
-		handler(msg.Data) // Memproses payload sesuai dengan subject-nya
+		meta := dto.EventMetaDTO{
+			Source:    msg.Subject,
+			MessageID: msg.Header.Get(nats.MsgIdHdr),
+		}
+
+		handler(msg.Data, meta) // Memproses payload sesuai dengan subject-nya
 	})
 
 	if err != nil {
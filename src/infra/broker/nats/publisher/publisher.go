@@ -0,0 +1,39 @@
+package publisher
+
+import (
+	"encoding/json"
+	"errors"
+
+	natsBroker "todo_list_consumer/src/infra/broker/nats"
+)
+
+// Interface untuk mengirim event ke NATS
+type PublisherInterface interface {
+	Publish(subject string, payload interface{}) error
+}
+
+// Struct implementasi publisher
+type natsPublisher struct {
+	nats *natsBroker.Nats // Instance NATS connection
+}
+
+// Konstruktor untuk membuat publisher
+func NewPublisher(Nats *natsBroker.Nats) PublisherInterface {
+	return &natsPublisher{
+		nats: Nats,
+	}
+}
+
+// Publish mengubah payload menjadi JSON lalu mengirimnya ke subject yang diberikan
+func (p *natsPublisher) Publish(subject string, payload interface{}) error {
+	if !p.nats.Status {
+		return errors.New("NATS tidak aktif, event " + subject + " tidak dikirim")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return p.nats.Conn.Publish(subject, data)
+}
@@ -0,0 +1,158 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock adalah sumber waktu yang bisa diganti, sehingga logika yang bergantung pada waktu
+// bisa diuji tanpa menunggu waktu berjalan
+type Clock interface {
+	Now() time.Time
+	// After mengirim waktu clock ke channel setelah d berlalu menurut clock ini
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+// New mengembalikan clock yang memakai waktu sistem
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Fake adalah clock untuk test, waktunya hanya berubah lewat Set atau Advance.
+// Channel dari After baru terkirim saat Set atau Advance melewati deadline-nya
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+// fakeWaiter adalah pemanggilan After yang menunggu fake clock mencapai at
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFake membuat fake clock yang berhenti di waktu now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := fakeWaiter{at: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		return w.ch
+	}
+
+	f.waiters = append(f.waiters, w)
+	return w.ch
+}
+
+// Waiting mengembalikan deadline pemanggilan After yang belum terkirim, dipakai test untuk
+// menunggu sampai goroutine lain sudah tidur sebelum memajukan waktu
+func (f *Fake) Waiting() []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	at := []time.Time{}
+	for _, w := range f.waiters {
+		at = append(at, w.at)
+	}
+
+	return at
+}
+
+// Advance memajukan waktu sebesar d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	f.fire()
+}
+
+// Set mengganti waktu fake clock
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+	f.fire()
+}
+
+// fire mengirim waktu ke semua waiter yang deadline-nya sudah tercapai, f.mu harus sudah dikunci
+func (f *Fake) fire() {
+	waiting := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			waiting = append(waiting, w)
+			continue
+		}
+
+		w.ch <- f.now
+	}
+
+	f.waiters = waiting
+}
+
+// Offset adalah clock yang bisa digeser maju atau mundur dari clock dasarnya ("time travel").
+// Dipakai di development untuk mereproduksi bug expiry tanpa menunggu deadline
+type Offset struct {
+	base   Clock
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+// NewOffset membuat clock yang mengikuti base dengan pergeseran awal 0
+func NewOffset(base Clock) *Offset {
+	return &Offset{base: base}
+}
+
+func (o *Offset) Now() time.Time {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return o.base.Now().Add(o.offset)
+}
+
+// After menunggu d di clock dasar. Pergeseran yang diganti selama menunggu tidak mempercepat
+// atau memperlambat channel, pemanggil yang peduli harus membatasi lama tunggunya sendiri
+func (o *Offset) After(d time.Duration) <-chan time.Time {
+	return o.base.After(d)
+}
+
+// Offset mengembalikan pergeseran saat ini
+func (o *Offset) Offset() time.Duration {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return o.offset
+}
+
+// SetOffset mengganti pergeseran terhadap clock dasar, 0 mengembalikan clock ke waktu sebenarnya
+func (o *Offset) SetOffset(offset time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.offset = offset
+}
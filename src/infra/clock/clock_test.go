@@ -0,0 +1,42 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetFollowsBaseClock(t *testing.T) {
+	start := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	base := NewFake(start)
+	c := NewOffset(base)
+
+	assert.Equal(t, start, c.Now())
+
+	c.SetOffset(2 * time.Hour)
+	base.Advance(time.Minute)
+	assert.Equal(t, start.Add(2*time.Hour+time.Minute), c.Now())
+	assert.Equal(t, 2*time.Hour, c.Offset())
+
+	c.SetOffset(0)
+	assert.Equal(t, start.Add(time.Minute), c.Now())
+}
+
+func TestFakeAfterFiresOnAdvance(t *testing.T) {
+	start := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	ch := c.After(time.Minute)
+	assert.Equal(t, []time.Time{start.Add(time.Minute)}, c.Waiting())
+
+	c.Advance(time.Minute - time.Second)
+	assert.Empty(t, ch)
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-ch)
+	assert.Empty(t, c.Waiting())
+
+	// Durasi nol atau negatif langsung terkirim
+	assert.Equal(t, start.Add(time.Minute), <-c.After(0))
+}
@@ -3,17 +3,25 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"todo_list_consumer/src/infra/constants"
 )
 
 type AppConf struct {
 	Environment string
 	Name        string
+	TimeTravel  bool // Mengaktifkan endpoint /admin/clock untuk menggeser waktu service, ditolak di PRODUCTION
 }
 
 type HttpConf struct {
-	Port       string
-	XRequestID string
-	Timeout    int
+	Port         string
+	XRequestID   string
+	Timeout      int
+	UserIDHeader string // Header berisi id user yang sudah diautentikasi API gateway
+	InternalPort string // Port untuk endpoint internal (/debug/vars), tidak diekspos lewat API gateway
+	AdminToken   string // Bearer token endpoint /admin, kosong berarti endpoint admin dimatikan
 }
 
 type LogConf struct {
@@ -42,22 +50,74 @@ type NatsConf struct {
 }
 
 type RedisConf struct {
-	Host         string // Alamat host Redis
-	Port         string // Port Redis
-	PoolSize     int    // Maksimum jumlah koneksi dalam pool
-	MinIdleConns int    // Minimum koneksi idle yang dipertahankan
-	IdleTimeout  int    // Waktu sebelum koneksi idle ditutup
+	Mode         string   // standalone, sentinel atau cluster
+	Host         string   // Alamat host Redis
+	Port         string   // Port Redis
+	Addrs        []string // Alamat sentinel atau node cluster, standalone memakai Host:Port jika kosong
+	Username     string   // User ACL Redis 6+
+	Password     string   // Password Redis
+	DB           int      // Index database, cluster hanya mendukung 0
+	TLS          bool     // Koneksi memakai TLS
+	PoolSize     int      // Maksimum jumlah koneksi dalam pool
+	MinIdleConns int      // Minimum koneksi idle yang dipertahankan
+	IdleTimeout  int      // Waktu sebelum koneksi idle ditutup (menit)
+
+	MasterName       string // Nama master yang dipantau sentinel
+	SentinelUsername string // User ACL sentinel jika berbeda dengan Redis
+	SentinelPassword string // Password sentinel jika berbeda dengan Redis
+}
+
+type TaskConf struct {
+	MaxSnooze   int    // Batas maksimum snooze per task, 0 berarti tanpa batas
+	ChildPolicy string // Kebijakan subtask pending saat parent expired/dihapus: cascade atau detach
+
+	ReminderOffsets []time.Duration // Waktu sebelum expires_at untuk mengirim reminder, contoh: 24h,1h,10m
+
+	MaxPending   int           // Default batas task pending per user, 0 berarti tanpa batas
+	MaxCreated   int           // Default batas task yang dibuat per user dalam CreateWindow, 0 berarti tanpa batas
+	CreateWindow time.Duration // Jendela waktu untuk MaxCreated
 
+	AutoExtendInterval time.Duration // Perpanjangan deadline untuk kebijakan auto_extend
+	AutoExtendMax      int           // Batas perpanjangan auto_extend sebelum task expired
+	GracePeriod        time.Duration // Masa tenggang untuk kebijakan grace
+
+	DefaultTimezone string // Zona IANA untuk waktu tanpa offset jika pesan dan user tidak menentukan timezone
+
+	AllowAnonymousFinish bool // Menerima finishtask tanpa user_id dari producer lama tanpa pengecekan akses, default mati
+
+	ArchiveAfter     time.Duration // Umur (dari expires_at) task done/expired sebelum diarsip, 0 mematikan arsip
+	ArchiveInterval  time.Duration // Jeda antar putaran job arsip
+	ArchiveBatchSize int           // Jumlah task yang dipindahkan per batch
+
+	ReconcileInterval  time.Duration // Jeda antar putaran reconciler setelah putaran awal saat startup, 0 berarti hanya saat startup
+	ReconcileBatchSize int           // Jumlah task pending yang dibaca per batch
+}
+
+type SchedulerConf struct {
+	Backend      string        // keyspace (notifikasi expired Redis), zset (sorted set Redis yang durable), postgres (tanpa Redis) atau memory (development)
+	PollInterval time.Duration // Jeda polling jadwal yang jatuh tempo (backend zset, postgres dan memory)
+	BatchSize    int           // Jumlah jadwal yang diklaim per polling
+	Lease        time.Duration // Umur klaim jadwal, harus lebih panjang dari durasi handler: zset dan postgres mengklaim ulang setelah lease habis, keyspace menolak event ganda selama lease
+	MaxAttempts  int           // Batas percobaan sebelum jadwal yang terus gagal dibuang
+	InstanceID   string        // Identitas replica yang disimpan di klaim, default hostname
+
+	KeyspaceEvents string // Pengecekan notify-keyspace-events untuk backend keyspace: configure (aktifkan flag) atau verify (tolak start)
+
+	KeyPrefix   string // Prefix key Redis scheduler, default todo
+	Environment string // Bagian environment di key Redis, diambil dari APP_ENV
+	Tenant      string // Bagian tenant di key Redis, default default
 }
 
 // Config ...
 type Config struct {
-	App   AppConf
-	Http  HttpConf
-	Log   LogConf
-	SqlDb SqlDbConf
-	Nats  NatsConf
-	Redis RedisConf
+	App       AppConf
+	Http      HttpConf
+	Log       LogConf
+	SqlDb     SqlDbConf
+	Nats      NatsConf
+	Redis     RedisConf
+	Task      TaskConf
+	Scheduler SchedulerConf
 }
 
 // NewConfig ...
@@ -67,6 +127,11 @@ func Make() Config {
 		Name:        os.Getenv("APP_NAME"),
 	}
 
+	appTimeTravel, err := strconv.ParseBool(os.Getenv("APP_TIME_TRAVEL"))
+	if err == nil {
+		app.TimeTravel = appTimeTravel
+	}
+
 	sqldb := SqlDbConf{
 		Host:           os.Getenv("DB_HOST"),
 		Username:       os.Getenv("DB_USERNAME"),
@@ -108,8 +173,35 @@ func Make() Config {
 	}
 
 	redis := RedisConf{
-		Host: os.Getenv("REDIS_HOST"),
-		Port: os.Getenv("REDIS_PORT"),
+		Mode:             os.Getenv("REDIS_MODE"),
+		Host:             os.Getenv("REDIS_HOST"),
+		Port:             os.Getenv("REDIS_PORT"),
+		Username:         os.Getenv("REDIS_USERNAME"),
+		Password:         os.Getenv("REDIS_PASSWORD"),
+		MasterName:       os.Getenv("REDIS_SENTINEL_MASTER"),
+		SentinelUsername: os.Getenv("REDIS_SENTINEL_USERNAME"),
+		SentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+	}
+
+	// set default redis mode to standalone
+	if redis.Mode == "" {
+		redis.Mode = "standalone"
+	}
+
+	for _, addr := range strings.Split(os.Getenv("REDIS_ADDRS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			redis.Addrs = append(redis.Addrs, addr)
+		}
+	}
+
+	redisDB, err := strconv.Atoi(os.Getenv("REDIS_DB"))
+	if err == nil {
+		redis.DB = redisDB
+	}
+
+	redisTLS, err := strconv.ParseBool(os.Getenv("REDIS_TLS"))
+	if err == nil {
+		redis.TLS = redisTLS
 	}
 
 	redisPoolSize, err := strconv.Atoi(os.Getenv("REDIS_POOL_SIZE"))
@@ -127,9 +219,164 @@ func Make() Config {
 		redis.IdleTimeout = redisIdleTimeout
 	}
 
+	task := TaskConf{
+		MaxSnooze:   3,
+		ChildPolicy: os.Getenv("TASK_CHILD_POLICY"),
+	}
+
+	for _, offset := range strings.Split(os.Getenv("TASK_REMINDER_OFFSETS"), ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(offset))
+		if err == nil && d > 0 {
+			task.ReminderOffsets = append(task.ReminderOffsets, d)
+		}
+	}
+
+	// set default child policy to cascade
+	if task.ChildPolicy == "" {
+		task.ChildPolicy = constants.CHILD_POLICY_CASCADE
+	}
+
+	taskMaxSnooze, err := strconv.Atoi(os.Getenv("TASK_MAX_SNOOZE"))
+	if err == nil {
+		task.MaxSnooze = taskMaxSnooze
+	}
+
+	task.MaxPending = 1000
+	taskMaxPending, err := strconv.Atoi(os.Getenv("TASK_MAX_PENDING"))
+	if err == nil {
+		task.MaxPending = taskMaxPending
+	}
+
+	taskMaxCreated, err := strconv.Atoi(os.Getenv("TASK_MAX_CREATED"))
+	if err == nil {
+		task.MaxCreated = taskMaxCreated
+	}
+
+	task.CreateWindow = time.Hour
+	taskCreateWindow, err := time.ParseDuration(os.Getenv("TASK_CREATE_WINDOW"))
+	if err == nil && taskCreateWindow > 0 {
+		task.CreateWindow = taskCreateWindow
+	}
+
+	task.AutoExtendInterval = 24 * time.Hour
+	taskAutoExtendInterval, err := time.ParseDuration(os.Getenv("TASK_AUTO_EXTEND_INTERVAL"))
+	if err == nil && taskAutoExtendInterval > 0 {
+		task.AutoExtendInterval = taskAutoExtendInterval
+	}
+
+	task.AutoExtendMax = 3
+	taskAutoExtendMax, err := strconv.Atoi(os.Getenv("TASK_AUTO_EXTEND_MAX"))
+	if err == nil {
+		task.AutoExtendMax = taskAutoExtendMax
+	}
+
+	task.GracePeriod = time.Hour
+	taskGracePeriod, err := time.ParseDuration(os.Getenv("TASK_GRACE_PERIOD"))
+	if err == nil && taskGracePeriod >= 0 {
+		task.GracePeriod = taskGracePeriod
+	}
+
+	task.DefaultTimezone = os.Getenv("TASK_DEFAULT_TIMEZONE")
+	if task.DefaultTimezone == "" {
+		task.DefaultTimezone = "Asia/Jakarta"
+	}
+
+	taskAllowAnonymousFinish, err := strconv.ParseBool(os.Getenv("TASK_ALLOW_ANONYMOUS_FINISH"))
+	if err == nil {
+		task.AllowAnonymousFinish = taskAllowAnonymousFinish
+	}
+
+	task.ArchiveAfter = 30 * 24 * time.Hour
+	taskArchiveAfter, err := time.ParseDuration(os.Getenv("TASK_ARCHIVE_AFTER"))
+	if err == nil {
+		task.ArchiveAfter = taskArchiveAfter
+	}
+
+	task.ArchiveInterval = time.Hour
+	taskArchiveInterval, err := time.ParseDuration(os.Getenv("TASK_ARCHIVE_INTERVAL"))
+	if err == nil && taskArchiveInterval > 0 {
+		task.ArchiveInterval = taskArchiveInterval
+	}
+
+	task.ArchiveBatchSize = 500
+	taskArchiveBatchSize, err := strconv.Atoi(os.Getenv("TASK_ARCHIVE_BATCH_SIZE"))
+	if err == nil && taskArchiveBatchSize > 0 {
+		task.ArchiveBatchSize = taskArchiveBatchSize
+	}
+
+	task.ReconcileInterval = 5 * time.Minute
+	taskReconcileInterval, err := time.ParseDuration(os.Getenv("TASK_RECONCILE_INTERVAL"))
+	if err == nil && taskReconcileInterval >= 0 {
+		task.ReconcileInterval = taskReconcileInterval
+	}
+
+	task.ReconcileBatchSize = 500
+	taskReconcileBatchSize, err := strconv.Atoi(os.Getenv("TASK_RECONCILE_BATCH_SIZE"))
+	if err == nil && taskReconcileBatchSize > 0 {
+		task.ReconcileBatchSize = taskReconcileBatchSize
+	}
+
+	scheduler := SchedulerConf{
+		Backend:      os.Getenv("SCHEDULER_BACKEND"),
+		PollInterval: time.Second,
+		BatchSize:    100,
+		Lease:        30 * time.Second,
+		MaxAttempts:  5,
+	}
+
+	// set default scheduler backend to keyspace notification
+	if scheduler.Backend == "" {
+		scheduler.Backend = "keyspace"
+	}
+
+	// set default key namespace
+	scheduler.KeyPrefix = os.Getenv("SCHEDULER_KEY_PREFIX")
+	if scheduler.KeyPrefix == "" {
+		scheduler.KeyPrefix = "todo"
+	}
+
+	scheduler.Tenant = os.Getenv("SCHEDULER_TENANT")
+	if scheduler.Tenant == "" {
+		scheduler.Tenant = "default"
+	}
+
+	// set default keyspace events check to configure
+	scheduler.KeyspaceEvents = os.Getenv("SCHEDULER_KEYSPACE_EVENTS")
+	if scheduler.KeyspaceEvents == "" {
+		scheduler.KeyspaceEvents = "configure"
+	}
+
+	scheduler.InstanceID = os.Getenv("SCHEDULER_INSTANCE_ID")
+	if scheduler.InstanceID == "" {
+		scheduler.InstanceID, _ = os.Hostname()
+	}
+
+	schedulerPollInterval, err := time.ParseDuration(os.Getenv("SCHEDULER_POLL_INTERVAL"))
+	if err == nil && schedulerPollInterval > 0 {
+		scheduler.PollInterval = schedulerPollInterval
+	}
+
+	schedulerBatchSize, err := strconv.Atoi(os.Getenv("SCHEDULER_BATCH_SIZE"))
+	if err == nil && schedulerBatchSize > 0 {
+		scheduler.BatchSize = schedulerBatchSize
+	}
+
+	schedulerLease, err := time.ParseDuration(os.Getenv("SCHEDULER_LEASE"))
+	if err == nil && schedulerLease > 0 {
+		scheduler.Lease = schedulerLease
+	}
+
+	schedulerMaxAttempts, err := strconv.Atoi(os.Getenv("SCHEDULER_MAX_ATTEMPTS"))
+	if err == nil && schedulerMaxAttempts > 0 {
+		scheduler.MaxAttempts = schedulerMaxAttempts
+	}
+
 	http := HttpConf{
-		Port:       os.Getenv("HTTP_PORT"),
-		XRequestID: os.Getenv("HTTP_REQUEST_ID"),
+		Port:         os.Getenv("HTTP_PORT"),
+		XRequestID:   os.Getenv("HTTP_REQUEST_ID"),
+		UserIDHeader: os.Getenv("HTTP_USER_ID_HEADER"),
+		InternalPort: os.Getenv("HTTP_INTERNAL_PORT"),
+		AdminToken:   os.Getenv("HTTP_ADMIN_TOKEN"),
 	}
 
 	log := LogConf{
@@ -141,23 +388,38 @@ func Make() Config {
 		app.Environment = "LOCAL"
 	}
 
+	// key scheduler dipisahkan per environment agar environment yang berbagi Redis tidak bertabrakan
+	scheduler.Environment = strings.ToLower(app.Environment)
+
 	// set default port for HTTP
 	if http.Port == "" {
 		http.Port = "8080"
 	}
 
+	// set default port untuk endpoint internal
+	if http.InternalPort == "" {
+		http.InternalPort = "9090"
+	}
+
+	// set default header id user dari API gateway
+	if http.UserIDHeader == "" {
+		http.UserIDHeader = "X-User-ID"
+	}
+
 	httpTimeout, err := strconv.Atoi(os.Getenv("HTTP_TIMEOUT"))
 	if err == nil {
 		http.Timeout = httpTimeout
 	}
 
 	config := Config{
-		App:   app,
-		Http:  http,
-		Log:   log,
-		SqlDb: sqldb,
-		Nats:  nats,
-		Redis: redis,
+		App:       app,
+		Http:      http,
+		Log:       log,
+		SqlDb:     sqldb,
+		Nats:      nats,
+		Redis:     redis,
+		Task:      task,
+		Scheduler: scheduler,
 	}
 
 	return config
@@ -1,7 +1,69 @@
 package constants
 
 const (
-	ADD_TASK    = "addtask"
-	FINISH_TASK = "finishtask"
-	TASK_QUEUE  = "taskQueue"
+	ADD_TASK          = "addtask"
+	FINISH_TASK       = "finishtask"
+	SNOOZE_TASK       = "snoozetask"
+	UPDATE_SERIES     = "updateseries"
+	STOP_SERIES       = "stopseries"
+	DELETE_TASK       = "deletetask"
+	ADD_TAGS          = "addtags"
+	REMOVE_TAGS       = "removetags"
+	CHANGE_PRIORITY   = "changepriority"
+	ASSIGN_TASK       = "assigntask"
+	ADD_DEPENDENCY    = "adddependency"
+	REMOVE_DEPENDENCY = "removedependency"
+	SET_TIMEZONE      = "settimezone"
+	START_TIMER       = "starttimer"
+	STOP_TIMER        = "stoptimer"
+	TASK_QUEUE        = "taskQueue"
+)
+
+// Subject event yang dikirim consumer ketika status task berubah
+const (
+	TASK_FINISHED_EVENT = "task.finished"
+	TASK_EXPIRED_EVENT  = "task.expired"
+	TASK_DELETED_EVENT  = "task.deleted"
+	TASK_DETACHED_EVENT = "task.detached"
+	TASK_REMINDER_EVENT = "task.reminder"
+	TASK_ASSIGNED_EVENT = "task.assigned"
+	TASK_FLAGGED_EVENT  = "task.flagged"
+	TASK_OVERDUE_EVENT  = "task.overdue"
+	TASK_EXTENDED_EVENT = "task.extended"
+)
+
+// Kebijakan untuk subtask pending ketika parent-nya expired atau dihapus
+const (
+	CHILD_POLICY_CASCADE = "cascade" // subtask ikut expired/dihapus
+	CHILD_POLICY_DETACH  = "detach"  // subtask dilepas dari parent dan tetap pending
+)
+
+// Kebijakan ketika deadline task terlewati
+const (
+	EXPIRY_POLICY_EXPIRE      = "expire"      // task langsung expired
+	EXPIRY_POLICY_NOTIFY_ONLY = "notify_only" // kirim event overdue, task tetap pending
+	EXPIRY_POLICY_AUTO_EXTEND = "auto_extend" // deadline dimundurkan otomatis sampai batas tertentu
+	EXPIRY_POLICY_GRACE       = "grace"       // kirim event overdue, expired setelah masa tenggang
+)
+
+// Level prioritas task
+const (
+	PRIORITY_LOW    = "low"
+	PRIORITY_MEDIUM = "medium"
+	PRIORITY_HIGH   = "high"
+	PRIORITY_URGENT = "urgent"
+)
+
+// Hak akses kolaborator pada task
+const (
+	PERMISSION_VIEW     = "view"     // hanya bisa melihat dan menerima notifikasi
+	PERMISSION_COMPLETE = "complete" // bisa mengubah dan menyelesaikan task
+)
+
+// Sumber perubahan task selain subject NATS, dicatat di riwayat task
+const (
+	SOURCE_SCHEDULER  = "scheduler"
+	SOURCE_ADMIN      = "admin"
+	SOURCE_ARCHIVER   = "archiver"
+	SOURCE_RECONCILER = "reconciler"
 )
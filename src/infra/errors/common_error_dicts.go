@@ -9,6 +9,18 @@ const (
 	FAILED_CREATE_DATA     ErrorCode = 1005
 	USER_ALREADY_EXIST     ErrorCode = 1006
 	FAILED_SENDING_MESSAGE ErrorCode = 1007
+	TASK_NOT_FOUND         ErrorCode = 1008
+	TASK_NOT_PENDING       ErrorCode = 1009
+	SNOOZE_LIMIT_REACHED   ErrorCode = 1010
+	SERIES_NOT_FOUND       ErrorCode = 1011
+	PARENT_TASK_INVALID    ErrorCode = 1012
+	TASK_FORBIDDEN         ErrorCode = 1013
+	TASK_BLOCKED           ErrorCode = 1014
+	DEPENDENCY_CYCLE       ErrorCode = 1015
+	TASK_QUOTA_EXCEEDED    ErrorCode = 1016
+	TIMER_ALREADY_RUNNING  ErrorCode = 1017
+	TIMER_NOT_RUNNING      ErrorCode = 1018
+	SERVICE_NOT_READY      ErrorCode = 1019
 )
 
 var errorCodes = map[ErrorCode]*CommonError{
@@ -51,4 +63,64 @@ var errorCodes = map[ErrorCode]*CommonError{
 		SystemMessage: "message_cant_be_send.",
 		ErrorCode:     FAILED_SENDING_MESSAGE,
 	},
+	TASK_NOT_FOUND: {
+		ClientMessage: "Task not found.",
+		SystemMessage: "Task not found.",
+		ErrorCode:     TASK_NOT_FOUND,
+	},
+	TASK_NOT_PENDING: {
+		ClientMessage: "Task is not pending.",
+		SystemMessage: "Task is no longer pending.",
+		ErrorCode:     TASK_NOT_PENDING,
+	},
+	SNOOZE_LIMIT_REACHED: {
+		ClientMessage: "Snooze limit reached.",
+		SystemMessage: "Task has reached the maximum number of snoozes.",
+		ErrorCode:     SNOOZE_LIMIT_REACHED,
+	},
+	SERIES_NOT_FOUND: {
+		ClientMessage: "Task series not found.",
+		SystemMessage: "Task series not found.",
+		ErrorCode:     SERIES_NOT_FOUND,
+	},
+	PARENT_TASK_INVALID: {
+		ClientMessage: "Invalid parent task.",
+		SystemMessage: "Parent task does not exist or is no longer pending.",
+		ErrorCode:     PARENT_TASK_INVALID,
+	},
+	TASK_FORBIDDEN: {
+		ClientMessage: "You are not allowed to modify this task.",
+		SystemMessage: "User does not have permission on this task.",
+		ErrorCode:     TASK_FORBIDDEN,
+	},
+	TASK_BLOCKED: {
+		ClientMessage: "Task is blocked by unfinished tasks.",
+		SystemMessage: "Task still has unfinished blockers.",
+		ErrorCode:     TASK_BLOCKED,
+	},
+	DEPENDENCY_CYCLE: {
+		ClientMessage: "Dependency would create a cycle.",
+		SystemMessage: "Task dependency would create a cycle.",
+		ErrorCode:     DEPENDENCY_CYCLE,
+	},
+	TASK_QUOTA_EXCEEDED: {
+		ClientMessage: "Task quota exceeded.",
+		SystemMessage: "User has reached the pending task or task creation limit.",
+		ErrorCode:     TASK_QUOTA_EXCEEDED,
+	},
+	TIMER_ALREADY_RUNNING: {
+		ClientMessage: "Timer is already running.",
+		SystemMessage: "User already has an open session on this task.",
+		ErrorCode:     TIMER_ALREADY_RUNNING,
+	},
+	TIMER_NOT_RUNNING: {
+		ClientMessage: "Timer is not running.",
+		SystemMessage: "User has no open session on this task.",
+		ErrorCode:     TIMER_NOT_RUNNING,
+	},
+	SERVICE_NOT_READY: {
+		ClientMessage: "Service is not ready.",
+		SystemMessage: "One or more readiness checks failed.",
+		ErrorCode:     SERVICE_NOT_READY,
+	},
 }
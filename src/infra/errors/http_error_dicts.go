@@ -11,4 +11,16 @@ var httpCode = map[ErrorCode]int{
 	UNAUTHORIZED:          http.StatusUnauthorized,
 	FAILED_RETRIEVE_DATA:  http.StatusInternalServerError,
 	USER_ALREADY_EXIST:    http.StatusConflict,
+	TASK_NOT_FOUND:        http.StatusNotFound,
+	TASK_NOT_PENDING:      http.StatusConflict,
+	SNOOZE_LIMIT_REACHED:  http.StatusUnprocessableEntity,
+	SERIES_NOT_FOUND:      http.StatusNotFound,
+	PARENT_TASK_INVALID:   http.StatusBadRequest,
+	TASK_FORBIDDEN:        http.StatusForbidden,
+	TASK_BLOCKED:          http.StatusConflict,
+	DEPENDENCY_CYCLE:      http.StatusConflict,
+	TASK_QUOTA_EXCEEDED:   http.StatusTooManyRequests,
+	TIMER_ALREADY_RUNNING: http.StatusConflict,
+	TIMER_NOT_RUNNING:     http.StatusConflict,
+	SERVICE_NOT_READY:     http.StatusServiceUnavailable,
 }
@@ -0,0 +1,23 @@
+package metrics
+
+import "expvar"
+
+// Counter dipublikasikan lewat expvar dan bisa dibaca di endpoint /debug/vars pada port internal
+var (
+	// quotaRejections menghitung task yang ditolak karena kuota, per jenis batas (pending/created)
+	quotaRejections = expvar.NewMap("task_quota_rejections_total")
+	// reconciled menghitung tindakan reconciler, per jenis tindakan (expired/rescheduled/orphaned/failed)
+	reconciled = expvar.NewMap("task_reconcile_total")
+)
+
+// QuotaRejected menambah counter penolakan kuota untuk jenis batas tertentu
+func QuotaRejected(limit string) {
+	quotaRejections.Add(limit, 1)
+}
+
+// Reconciled menambah counter tindakan reconciler sebanyak n
+func Reconciled(action string, n int64) {
+	if n > 0 {
+		reconciled.Add(action, n)
+	}
+}
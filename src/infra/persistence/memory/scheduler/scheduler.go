@@ -0,0 +1,302 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+)
+
+// entry adalah satu jadwal di antrian, index adalah posisinya di heap
+type entry struct {
+	key      infra_scheduler.ScheduledKey
+	at       time.Time
+	index    int
+	attempts int                     // jumlah dispatch yang gagal
+	backoff  infra_scheduler.Backoff // jeda percobaan ulang setelah dispatch gagal
+}
+
+// entryHeap adalah min-heap jadwal berdasarkan waktu jatuh tempo
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// memorySchedulerService menyimpan jadwal di memori proses, untuk development lokal dan test.
+// Jadwal hilang saat proses berhenti (reconciler membuatnya ulang saat startup) dan tidak dibagi
+// antar replica, jangan dipakai di production
+type memorySchedulerService struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	handler infra_scheduler.TaskHandler
+	conf    config.SchedulerConf
+	entries map[infra_scheduler.ScheduledKey]*entry
+	queue   entryHeap
+	wake    chan struct{} // membangunkan worker saat ada jadwal yang lebih awal
+	state   infra_scheduler.WorkerState
+}
+
+// NewMemorySchedulerService membuat scheduler in-memory yang membaca waktu dari c
+func NewMemorySchedulerService(c clock.Clock, conf config.SchedulerConf) infra_scheduler.SchedulerInterface {
+	return &memorySchedulerService{
+		clock:   c,
+		conf:    conf,
+		entries: map[infra_scheduler.ScheduledKey]*entry{},
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+func (s *memorySchedulerService) RegisterHandler(h infra_scheduler.TaskHandler) {
+	s.handler = h
+}
+
+func (s *memorySchedulerService) ScheduleTaskCancellation(taskID int64, expiresAt time.Time) error {
+	if !expiresAt.After(s.clock.Now()) {
+		log.Println("Waktu kedaluwarsa sudah lewat, tidak bisa menjadwalkan pembatalan.")
+		return errors.New("expiration sudah lampau")
+	}
+
+	s.add(infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindExpire, TaskID: taskID}, expiresAt)
+	return nil
+}
+
+func (s *memorySchedulerService) ExtendTaskCancellation(taskID int64, expiresAt time.Time) error {
+	if !expiresAt.After(s.clock.Now()) {
+		return errors.New("expiration sudah lampau")
+	}
+
+	s.add(infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindExpire, TaskID: taskID}, expiresAt)
+	return nil
+}
+
+func (s *memorySchedulerService) CancelTaskCancellation(taskID int64) error {
+	s.remove(infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindExpire, TaskID: taskID})
+	return nil
+}
+
+func (s *memorySchedulerService) ScheduleTaskReminders(taskID int64, expiresAt time.Time, offsets []time.Duration) error {
+	now := s.clock.Now()
+	for _, offset := range offsets {
+		at := expiresAt.Add(-offset)
+		if !at.After(now) {
+			continue
+		}
+
+		s.add(infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindRemind, TaskID: taskID, Offset: offset}, at)
+	}
+
+	return nil
+}
+
+func (s *memorySchedulerService) CancelTaskReminders(taskID int64) error {
+	s.mu.Lock()
+	keys := []infra_scheduler.ScheduledKey{}
+	for key := range s.entries {
+		if key.Kind == infra_scheduler.KindRemind && key.TaskID == taskID {
+			keys = append(keys, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, key := range keys {
+		s.remove(key)
+	}
+
+	return nil
+}
+
+func (s *memorySchedulerService) ScheduledTaskIDs() ([]int64, error) {
+	return s.taskIDs(infra_scheduler.KindExpire), nil
+}
+
+func (s *memorySchedulerService) ReminderTaskIDs() ([]int64, error) {
+	return s.taskIDs(infra_scheduler.KindRemind), nil
+}
+
+// taskIDs mengumpulkan ID task unik yang memiliki jadwal berjenis kind
+func (s *memorySchedulerService) taskIDs(kind string) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[int64]bool{}
+	ids := []int64{}
+	for key := range s.entries {
+		if key.Kind == kind && !seen[key.TaskID] {
+			seen[key.TaskID] = true
+			ids = append(ids, key.TaskID)
+		}
+	}
+
+	return ids
+}
+
+func (s *memorySchedulerService) ScheduledExpiry(taskID int64) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindExpire, TaskID: taskID}]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	return e.at, true, nil
+}
+
+func (s *memorySchedulerService) UpcomingExpirations(from time.Time, to time.Time, limit int) ([]infra_scheduler.ScheduledExpiry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expirations := []infra_scheduler.ScheduledExpiry{}
+	for key, e := range s.entries {
+		if key.Kind == infra_scheduler.KindExpire && !e.at.Before(from) && !e.at.After(to) {
+			expirations = append(expirations, infra_scheduler.ScheduledExpiry{TaskID: key.TaskID, ExpiresAt: e.at})
+		}
+	}
+
+	return infra_scheduler.SortExpirations(expirations, limit), nil
+}
+
+func (s *memorySchedulerService) Status() infra_scheduler.WorkerStatus {
+	return s.state.Get()
+}
+
+// StartWorker tidur di clock sampai jadwal terdekat jatuh tempo, sehingga fake clock membangunkan
+// worker tepat saat waktunya dimajukan melewati jadwal. Lama tidur dibatasi PollInterval agar
+// pergeseran clock Offset tetap terbaca. Worker berhenti saat ctx dibatalkan
+func (s *memorySchedulerService) StartWorker(ctx context.Context) {
+	log.Println("Worker scheduler in-memory berjalan...")
+	s.state.Set(infra_scheduler.WorkerSubscribed)
+	defer s.state.Set(infra_scheduler.WorkerStopped)
+
+	for {
+		s.runDue()
+
+		wait := s.conf.PollInterval
+		if next, ok := s.next(); ok {
+			if d := next.Sub(s.clock.Now()); d < wait {
+				wait = d
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(wait):
+		case <-s.wake:
+		}
+	}
+}
+
+// runDue mengeluarkan semua jadwal yang sudah jatuh tempo menurut clock lalu memprosesnya berurutan
+func (s *memorySchedulerService) runDue() {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	due := []*entry{}
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		e := heap.Pop(&s.queue).(*entry)
+		delete(s.entries, e.key)
+		due = append(due, e)
+	}
+	s.mu.Unlock()
+
+	for _, e := range due {
+		if err := infra_scheduler.Dispatch(s.handler, e.key); err != nil {
+			s.retry(e, err)
+		}
+	}
+}
+
+// retry memasang ulang jadwal yang gagal diproses dengan jeda backoff sampai MaxAttempts tercapai.
+// Jadwal yang dipasang ulang selama handler berjalan tidak ditimpa
+func (s *memorySchedulerService) retry(e *entry, err error) {
+	e.attempts++
+	if e.attempts >= s.conf.MaxAttempts {
+		log.Printf("Jadwal %s dibuang setelah %d percobaan: %+v", e.key.Member(), e.attempts, err)
+		return
+	}
+
+	wait := e.backoff.Next()
+	log.Printf("Jadwal %s gagal diproses (percobaan %d), dicoba lagi dalam %s: %+v", e.key.Member(), e.attempts, wait, err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[e.key]; ok {
+		return
+	}
+
+	e.at = s.clock.Now().Add(wait)
+	s.entries[e.key] = e
+	heap.Push(&s.queue, e)
+}
+
+// next mengembalikan waktu jadwal terdekat
+func (s *memorySchedulerService) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+
+	return s.queue[0].at, true
+}
+
+// add membuat jadwal baru atau memindahkan waktu jadwal yang sudah ada. Jadwal yang dipindahkan
+// dihitung ulang percobaannya dari nol
+func (s *memorySchedulerService) add(key infra_scheduler.ScheduledKey, at time.Time) {
+	s.mu.Lock()
+	if e, ok := s.entries[key]; ok {
+		e.at = at
+		e.attempts = 0
+		e.backoff.Reset()
+		heap.Fix(&s.queue, e.index)
+	} else {
+		e = &entry{key: key, at: at, backoff: infra_scheduler.Backoff{Min: s.conf.PollInterval, Max: 30 * time.Second}}
+		s.entries[key] = e
+		heap.Push(&s.queue, e)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *memorySchedulerService) remove(key infra_scheduler.ScheduledKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		heap.Remove(&s.queue, e.index)
+		delete(s.entries, key)
+	}
+}
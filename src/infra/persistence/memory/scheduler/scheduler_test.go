@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+	"todo_list_consumer/src/infra/scheduler/schedulertest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScheduler(t *testing.T) (*memorySchedulerService, *clock.Fake, *schedulertest.Handler) {
+	c := clock.NewFake(time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC))
+	h := &schedulertest.Handler{}
+
+	s := NewMemorySchedulerService(c, config.SchedulerConf{PollInterval: time.Second}).(*memorySchedulerService)
+	s.RegisterHandler(h)
+
+	return s, c, h
+}
+
+func TestExpireCalledAtDeadline(t *testing.T) {
+	s, c, h := newTestScheduler(t)
+
+	assert.NoError(t, s.ScheduleTaskCancellation(1, c.Now().Add(10*time.Minute)))
+
+	c.Advance(10*time.Minute - time.Second)
+	s.runDue()
+	assert.Empty(t, h.Calls())
+
+	c.Advance(time.Second)
+	s.runDue()
+	assert.Equal(t, []string{"expire:1"}, h.Calls())
+
+	// Jadwal hanya diproses sekali
+	c.Advance(time.Hour)
+	s.runDue()
+	assert.Equal(t, []string{"expire:1"}, h.Calls())
+}
+
+func TestWorkerWakesWhenClockPassesDeadline(t *testing.T) {
+	s, c, h := newTestScheduler(t)
+	s.conf.PollInterval = 24 * time.Hour
+	deadline := c.Now().Add(10 * time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.StartWorker(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Worker harus sudah tidur menunggu deadline sebelum waktu dimajukan
+	assert.NoError(t, s.ScheduleTaskCancellation(1, deadline))
+	assert.Eventually(t, func() bool {
+		return containsTime(c.Waiting(), deadline)
+	}, time.Second, time.Millisecond)
+
+	c.Advance(10*time.Minute - time.Second)
+	assert.Empty(t, h.Calls())
+
+	c.Advance(time.Second)
+	assert.Eventually(t, func() bool {
+		return len(h.Calls()) > 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"expire:1"}, h.Calls())
+}
+
+func TestScheduleOrderExtendAndCancel(t *testing.T) {
+	s, c, h := newTestScheduler(t)
+	deadline := c.Now().Add(time.Hour)
+
+	assert.NoError(t, s.ScheduleTaskCancellation(1, deadline))
+	assert.NoError(t, s.ScheduleTaskCancellation(2, deadline.Add(-30*time.Minute)))
+	assert.NoError(t, s.ScheduleTaskCancellation(3, deadline))
+	assert.NoError(t, s.ScheduleTaskReminders(1, deadline, []time.Duration{10 * time.Minute, 2 * time.Hour}))
+
+	assert.NoError(t, s.ExtendTaskCancellation(1, deadline.Add(time.Hour)))
+	assert.NoError(t, s.CancelTaskCancellation(3))
+
+	ids, _ := s.ScheduledTaskIDs()
+	assert.ElementsMatch(t, []int64{1, 2}, ids)
+
+	ids, _ = s.ReminderTaskIDs()
+	assert.Equal(t, []int64{1}, ids)
+
+	c.Advance(2 * time.Hour)
+	s.runDue()
+
+	// Reminder 2 jam sebelum deadline sudah lewat saat dijadwalkan sehingga dilewati
+	assert.Equal(t, []string{"expire:2", "remind:1:10m0s", "expire:1"}, h.Calls())
+}
+
+func TestSchedulePastDeadlineRejected(t *testing.T) {
+	s, c, _ := newTestScheduler(t)
+
+	assert.Error(t, s.ScheduleTaskCancellation(1, c.Now()))
+	assert.Error(t, s.ExtendTaskCancellation(1, c.Now().Add(-time.Minute)))
+}
+
+func TestScheduledExpiryAndUpcoming(t *testing.T) {
+	s, c, _ := newTestScheduler(t)
+	now := c.Now()
+
+	assert.NoError(t, s.ScheduleTaskCancellation(1, now.Add(30*time.Minute)))
+	assert.NoError(t, s.ScheduleTaskCancellation(2, now.Add(10*time.Minute)))
+	assert.NoError(t, s.ScheduleTaskCancellation(3, now.Add(2*time.Hour)))
+	assert.NoError(t, s.ScheduleTaskReminders(1, now.Add(30*time.Minute), []time.Duration{10 * time.Minute}))
+
+	at, ok, err := s.ScheduledExpiry(1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(30*time.Minute), at)
+
+	_, ok, _ = s.ScheduledExpiry(4)
+	assert.False(t, ok)
+
+	upcoming, err := s.UpcomingExpirations(now, now.Add(time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []infra_scheduler.ScheduledExpiry{
+		{TaskID: 2, ExpiresAt: now.Add(10 * time.Minute)},
+		{TaskID: 1, ExpiresAt: now.Add(30 * time.Minute)},
+	}, upcoming)
+
+	upcoming, _ = s.UpcomingExpirations(now, now.Add(time.Hour), 1)
+	assert.Len(t, upcoming, 1)
+}
+
+func TestCancelRemindersFromOldOffsets(t *testing.T) {
+	s, c, _ := newTestScheduler(t)
+	deadline := c.Now().Add(3 * time.Hour)
+
+	assert.NoError(t, s.ScheduleTaskReminders(1, deadline, []time.Duration{time.Hour, 2 * time.Hour}))
+	assert.NoError(t, s.ScheduleTaskReminders(2, deadline, []time.Duration{time.Hour}))
+	assert.NoError(t, s.CancelTaskReminders(1))
+
+	assert.Len(t, s.entries, 1)
+	_, ok := s.entries[infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindRemind, TaskID: 2, Offset: time.Hour}]
+	assert.True(t, ok)
+}
+
+func TestFailedDispatchRetriedWithBackoff(t *testing.T) {
+	c := clock.NewFake(time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC))
+	h := &schedulertest.Handler{}
+	h.FailWith(errors.New("db down"), -1)
+
+	s := NewMemorySchedulerService(c, config.SchedulerConf{PollInterval: time.Second, MaxAttempts: 3}).(*memorySchedulerService)
+	s.RegisterHandler(h)
+
+	assert.NoError(t, s.ScheduleTaskCancellation(1, c.Now().Add(time.Minute)))
+	c.Advance(time.Minute)
+	s.runDue()
+	assert.Equal(t, []int64{1}, h.Expired())
+
+	// Percobaan kedua setelah jeda 1 detik, ketiga setelah jeda 2 detik
+	c.Advance(time.Second)
+	s.runDue()
+	assert.Equal(t, []int64{1, 1}, h.Expired())
+
+	c.Advance(time.Second)
+	s.runDue()
+	assert.Equal(t, []int64{1, 1}, h.Expired())
+
+	c.Advance(time.Second)
+	s.runDue()
+	assert.Equal(t, []int64{1, 1, 1}, h.Expired())
+
+	// Dibuang setelah MaxAttempts
+	c.Advance(time.Hour)
+	s.runDue()
+	assert.Equal(t, []int64{1, 1, 1}, h.Expired())
+
+	ids, _ := s.ScheduledTaskIDs()
+	assert.Empty(t, ids)
+}
+
+func TestFailedDispatchSucceedsOnRetry(t *testing.T) {
+	s, c, h := newTestScheduler(t)
+	s.conf.MaxAttempts = 3
+	h.FailWith(errors.New("db down"), 1)
+
+	assert.NoError(t, s.ScheduleTaskReminders(1, c.Now().Add(time.Hour), []time.Duration{10 * time.Minute}))
+	c.Advance(50 * time.Minute)
+	s.runDue()
+
+	c.Advance(time.Second)
+	s.runDue()
+	assert.Equal(t, []string{"remind:1:10m0s", "remind:1:10m0s"}, h.Calls())
+
+	ids, _ := s.ReminderTaskIDs()
+	assert.Empty(t, ids)
+}
+
+func containsTime(times []time.Time, at time.Time) bool {
+	for _, t := range times {
+		if t.Equal(at) {
+			return true
+		}
+	}
+
+	return false
+}
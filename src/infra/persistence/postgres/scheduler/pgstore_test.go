@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+	"todo_list_consumer/src/infra/scheduler/schedulertest"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test di file ini menjalankan query klaim dan ack di Postgres sungguhan dan dilewati jika
+// SCHEDULER_TEST_DATABASE_URL kosong. Database harus sudah berisi skema aplikasi (migrations).
+// Clock dimulai jauh di masa lalu agar jadwal lain di database tidak ikut diklaim
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newPgScheduler(t *testing.T) (*postgresSchedulerService, *sqlx.DB, *clock.Fake, *schedulertest.Handler) {
+	dsn := os.Getenv("SCHEDULER_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("SCHEDULER_TEST_DATABASE_URL tidak diisi")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	c := clock.NewFake(pgEpoch)
+	h := &schedulertest.Handler{}
+	s := NewPostgresSchedulerService(db, c, config.SchedulerConf{Lease: time.Minute, BatchSize: 10, MaxAttempts: 3}).(*postgresSchedulerService)
+	s.RegisterHandler(h)
+
+	return s, db, c, h
+}
+
+// insertTask membuat task pending dengan jadwal expire pada at dan menghapusnya setelah test selesai
+func insertTask(t *testing.T, db *sqlx.DB, at time.Time) int64 {
+	var id int64
+	err := db.Get(&id, `INSERT INTO public.tasks (user_id, title, priority, status, expires_at, scheduled_at)
+		VALUES (0, 'scheduler test', 'low', 'pending', $1, $1) RETURNING id`, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM public.task_reminders WHERE task_id = $1`, id)
+		db.Exec(`DELETE FROM public.tasks WHERE id = $1`, id)
+	})
+
+	return id
+}
+
+func scheduledAt(t *testing.T, db *sqlx.DB, id int64) *time.Time {
+	var at *time.Time
+	if err := db.Get(&at, `SELECT scheduled_at FROM public.tasks WHERE id = $1`, id); err != nil {
+		t.Fatal(err)
+	}
+
+	return at
+}
+
+func TestPgClaimRedeliveredAfterLease(t *testing.T) {
+	s, db, c, h := newPgScheduler(t)
+	ctx := context.Background()
+	id := insertTask(t, db, c.Now())
+
+	// Replica mati setelah klaim sehingga jadwal tidak pernah di-ack
+	claimed, err := s.store.claim(ctx, infra_scheduler.KindExpire, c.Now(), c.Now().Add(s.conf.Lease), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{id}, claimedIDs(claimed))
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	// Selama lease belum habis replica lain tidak mengklaim jadwal yang sama
+	c.Advance(s.conf.Lease - time.Second)
+	assert.NoError(t, s.poll(ctx, infra_scheduler.KindExpire))
+	assert.Empty(t, h.Expired())
+
+	// Setelah lease habis jadwal dikirim ulang dan di-ack
+	c.Advance(time.Second)
+	assert.NoError(t, s.poll(ctx, infra_scheduler.KindExpire))
+	assert.Equal(t, []int64{id}, h.Expired())
+	assert.Nil(t, scheduledAt(t, db, id))
+
+	// Ack terlambat dari klaim yang mati tidak menghapus jadwal yang dipasang ulang
+	assert.NoError(t, s.ScheduleTaskCancellation(id, c.Now().Add(time.Hour)))
+	assert.NoError(t, s.store.ack(ctx, infra_scheduler.KindExpire, claimed[0]))
+	assert.NotNil(t, scheduledAt(t, db, id))
+}
+
+func TestPgFailedClaimRetriedUntilMaxAttempts(t *testing.T) {
+	s, db, c, h := newPgScheduler(t)
+	id := insertTask(t, db, c.Now())
+	h.FailWith(errors.New("db down"), -1)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+		c.Advance(s.conf.Lease)
+	}
+
+	assert.Equal(t, []int64{id, id, id}, h.Expired())
+	assert.Nil(t, scheduledAt(t, db, id))
+}
+
+func TestPgClaimSkipsLockedRows(t *testing.T) {
+	s, db, c, h := newPgScheduler(t)
+	id := insertTask(t, db, c.Now())
+
+	// Baris yang sedang dikunci transaksi lain dilewati, bukan ditunggu
+	tx := db.MustBegin()
+	tx.MustExec(`SELECT id FROM public.tasks WHERE id = $1 FOR UPDATE`, id)
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Empty(t, h.Expired())
+	assert.NoError(t, tx.Rollback())
+
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Equal(t, []int64{id}, h.Expired())
+}
+
+func TestPgReminderRedeliveredAfterLease(t *testing.T) {
+	s, db, c, h := newPgScheduler(t)
+	ctx := context.Background()
+	id := insertTask(t, db, c.Now().Add(2*time.Hour))
+	assert.NoError(t, s.ScheduleTaskReminders(id, c.Now().Add(2*time.Hour), []time.Duration{time.Hour}))
+
+	c.Advance(time.Hour)
+	claimed, err := s.store.claim(ctx, infra_scheduler.KindRemind, c.Now(), c.Now().Add(s.conf.Lease), 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, int64(3600), claimed[0].OffsetSeconds)
+
+	c.Advance(s.conf.Lease)
+	assert.NoError(t, s.poll(ctx, infra_scheduler.KindRemind))
+	assert.Equal(t, []string{"remind:" + strconv.FormatInt(id, 10) + ":1h0m0s"}, h.Calls())
+
+	ids, err := s.ReminderTaskIDs()
+	assert.NoError(t, err)
+	assert.NotContains(t, ids, id)
+}
+
+func claimedIDs(claimed []claimedSchedule) []int64 {
+	ids := []int64{}
+	for _, c := range claimed {
+		ids = append(ids, c.TaskID)
+	}
+
+	return ids
+}
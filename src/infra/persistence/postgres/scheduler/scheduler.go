@@ -0,0 +1,358 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Query SQL backend scheduler postgres. Jadwal diklaim dengan FOR UPDATE SKIP LOCKED lalu scheduled_at/remind_at
+// diganti batas lease, sehingga replica lain melewati baris yang sama dan jadwal yang gagal diklaim ulang
+// setelah lease habis.
+//
+// Klaim sengaja tidak ditahan dalam satu transaksi selama handler berjalan: handler seperti ExpireTask mengubah
+// baris tasks yang sama di transaksinya sendiri sehingga akan menunggu row lock klaim. Akibatnya pengiriman
+// bersifat at-least-once:
+//   - replica yang mati di tengah handler meninggalkan batas lease di scheduled_at/remind_at, jadwal diklaim
+//     ulang replica lain setelah lease habis
+//   - ack hanya menghapus jadwal jika kolomnya masih berisi batas lease klaim tersebut, sehingga ack yang
+//     terlambat setelah jadwal diklaim ulang atau dipasang ulang tidak berpengaruh
+//   - handler harus idempoten, ExpireTask mengabaikan task yang sudah tidak pending
+//   - Lease harus lebih panjang dari durasi handler, jika tidak jadwal bisa diproses dua replica sekaligus
+//
+// Lease dan pengiriman ulang diuji terhadap Postgres di pgstore_test.go (SCHEDULER_TEST_DATABASE_URL)
+const (
+	SetSchedule = `UPDATE public.tasks SET scheduled_at = $2, schedule_attempts = 0 WHERE id = $1;`
+
+	ClearSchedule = `UPDATE public.tasks SET scheduled_at = NULL, schedule_attempts = 0 WHERE id = $1;`
+
+	ClaimSchedules = `UPDATE public.tasks t SET scheduled_at = $2, schedule_attempts = t.schedule_attempts + 1
+		FROM (SELECT id FROM public.tasks WHERE status = 'pending' AND scheduled_at <= $1
+			ORDER BY scheduled_at LIMIT $3 FOR UPDATE SKIP LOCKED) due
+		WHERE t.id = due.id
+		RETURNING t.id, t.scheduled_at, t.schedule_attempts`
+
+	// Jadwal hanya dihapus jika belum diganti handler, misalnya oleh kebijakan grace atau auto_extend
+	AckSchedule = `UPDATE public.tasks SET scheduled_at = NULL, schedule_attempts = 0 WHERE id = $1 AND scheduled_at = $2;`
+
+	GetScheduledTaskIDs = `SELECT id FROM public.tasks WHERE status = 'pending' AND scheduled_at IS NOT NULL`
+
+	// Selama diklaim worker scheduled_at berisi batas lease
+	GetScheduledExpiry = `SELECT scheduled_at FROM public.tasks WHERE id = $1 AND status = 'pending' AND scheduled_at IS NOT NULL`
+
+	GetUpcomingExpirations = `SELECT id AS task_id, scheduled_at AS expires_at FROM public.tasks
+		WHERE status = 'pending' AND scheduled_at BETWEEN $1 AND $2 ORDER BY scheduled_at, id LIMIT $3`
+
+	AddReminders = `INSERT INTO public.task_reminders (task_id, offset_seconds, remind_at)
+		SELECT $1, o, $2::timestamptz - make_interval(secs => o) FROM unnest($3::bigint[]) o
+		WHERE $2::timestamptz - make_interval(secs => o) > $4
+		ON CONFLICT (task_id, offset_seconds) DO UPDATE SET remind_at = EXCLUDED.remind_at, attempts = 0;`
+
+	RemoveReminders = `DELETE FROM public.task_reminders WHERE task_id = $1;`
+
+	GetReminderTaskIDs = `SELECT DISTINCT task_id FROM public.task_reminders`
+
+	ClaimReminders = `UPDATE public.task_reminders r SET remind_at = $2, attempts = r.attempts + 1
+		FROM (SELECT task_id, offset_seconds FROM public.task_reminders WHERE remind_at <= $1
+			ORDER BY remind_at LIMIT $3 FOR UPDATE SKIP LOCKED) due
+		WHERE r.task_id = due.task_id AND r.offset_seconds = due.offset_seconds
+		RETURNING r.task_id, r.offset_seconds, r.remind_at, r.attempts`
+
+	AckReminder = `DELETE FROM public.task_reminders WHERE task_id = $1 AND offset_seconds = $2 AND remind_at = $3;`
+)
+
+// claimedSchedule adalah satu jadwal yang berhasil diklaim, LeaseUntil dipakai saat ack
+type claimedSchedule struct {
+	TaskID        int64
+	OffsetSeconds int64 // hanya terisi untuk reminder
+	LeaseUntil    time.Time
+	Attempts      int
+}
+
+// scheduleStore mengklaim dan meng-ack jadwal jatuh tempo. Dipisah dari service agar alur klaim, retry
+// dan lease bisa diuji tanpa Postgres
+type scheduleStore interface {
+	claim(ctx context.Context, kind string, now time.Time, leaseUntil time.Time, limit int) ([]claimedSchedule, error)
+	ack(ctx context.Context, kind string, c claimedSchedule) error
+}
+
+// pgStore adalah scheduleStore yang menjalankan query klaim dan ack di Postgres
+type pgStore struct {
+	db *sqlx.DB
+}
+
+// postgresSchedulerService menyimpan jadwal di Postgres sehingga Redis tidak diperlukan.
+// Jadwal expire disimpan di public.tasks.scheduled_at, reminder di public.task_reminders
+type postgresSchedulerService struct {
+	db      *sqlx.DB
+	store   scheduleStore
+	handler infra_scheduler.TaskHandler
+	clock   clock.Clock
+	conf    config.SchedulerConf
+	state   infra_scheduler.WorkerState
+}
+
+// NewPostgresSchedulerService membuat scheduler yang mem-polling jadwal dari Postgres
+func NewPostgresSchedulerService(db *sqlx.DB, c clock.Clock, conf config.SchedulerConf) infra_scheduler.SchedulerInterface {
+	return &postgresSchedulerService{
+		db:    db,
+		store: pgStore{db: db},
+		clock: c,
+		conf:  conf,
+	}
+}
+
+func (s *postgresSchedulerService) RegisterHandler(h infra_scheduler.TaskHandler) {
+	s.handler = h
+}
+
+func (s *postgresSchedulerService) ScheduleTaskCancellation(taskID int64, expiresAt time.Time) error {
+	if !expiresAt.After(s.clock.Now()) {
+		log.Println("Waktu kedaluwarsa sudah lewat, tidak bisa menjadwalkan pembatalan.")
+		return errors.New("expiration sudah lampau")
+	}
+
+	return s.exec(SetSchedule, taskID, expiresAt)
+}
+
+func (s *postgresSchedulerService) ExtendTaskCancellation(taskID int64, expiresAt time.Time) error {
+	if !expiresAt.After(s.clock.Now()) {
+		return errors.New("expiration sudah lampau")
+	}
+
+	return s.exec(SetSchedule, taskID, expiresAt)
+}
+
+func (s *postgresSchedulerService) CancelTaskCancellation(taskID int64) error {
+	return s.exec(ClearSchedule, taskID)
+}
+
+func (s *postgresSchedulerService) ScheduleTaskReminders(taskID int64, expiresAt time.Time, offsets []time.Duration) error {
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	return s.exec(AddReminders, taskID, expiresAt, pq.Array(offsetSeconds(offsets)), s.clock.Now())
+}
+
+// CancelTaskReminders menghapus semua reminder task, termasuk offset yang sudah tidak ada di config
+func (s *postgresSchedulerService) CancelTaskReminders(taskID int64) error {
+	return s.exec(RemoveReminders, taskID)
+}
+
+func (s *postgresSchedulerService) ScheduledTaskIDs() ([]int64, error) {
+	ids := []int64{}
+	err := s.db.Select(&ids, GetScheduledTaskIDs)
+
+	if err != nil {
+		log.Println("Gagal membaca jadwal task:", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s *postgresSchedulerService) ReminderTaskIDs() ([]int64, error) {
+	ids := []int64{}
+	err := s.db.Select(&ids, GetReminderTaskIDs)
+
+	if err != nil {
+		log.Println("Gagal membaca jadwal reminder:", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s *postgresSchedulerService) ScheduledExpiry(taskID int64) (time.Time, bool, error) {
+	var at time.Time
+	err := s.db.Get(&at, GetScheduledExpiry, taskID)
+
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+
+	if err != nil {
+		log.Println("Gagal membaca jadwal task:", err)
+		return time.Time{}, false, err
+	}
+
+	return at, true, nil
+}
+
+func (s *postgresSchedulerService) UpcomingExpirations(from time.Time, to time.Time, limit int) ([]infra_scheduler.ScheduledExpiry, error) {
+	expirations := []infra_scheduler.ScheduledExpiry{}
+	err := s.db.Select(&expirations, GetUpcomingExpirations, from, to, limit)
+
+	if err != nil {
+		log.Println("Gagal membaca jadwal task:", err)
+		return nil, err
+	}
+
+	return expirations, nil
+}
+
+func (s *postgresSchedulerService) Status() infra_scheduler.WorkerStatus {
+	return s.state.Get()
+}
+
+// StartWorker mem-polling jadwal expire dan reminder yang jatuh tempo setiap PollInterval sampai ctx
+// dibatalkan. Selama Postgres gagal dihubungi jeda polling memakai backoff
+func (s *postgresSchedulerService) StartWorker(ctx context.Context) {
+	log.Println("Worker scheduler postgres berjalan...")
+	defer s.state.Set(infra_scheduler.WorkerStopped)
+
+	backoff := &infra_scheduler.Backoff{Min: s.conf.PollInterval, Max: 30 * time.Second}
+	for {
+		wait := s.conf.PollInterval
+
+		err := s.poll(ctx, infra_scheduler.KindExpire)
+		if err == nil {
+			err = s.poll(ctx, infra_scheduler.KindRemind)
+		}
+
+		if err != nil {
+			s.state.Set(infra_scheduler.WorkerReconnecting)
+			wait = backoff.Next()
+			log.Printf("Gagal mengklaim jadwal task, dicoba lagi dalam %s: %v", wait, err)
+		} else {
+			s.state.Set(infra_scheduler.WorkerSubscribed)
+			backoff.Reset()
+		}
+
+		if !infra_scheduler.Sleep(ctx, wait) {
+			log.Println("Worker scheduler postgres berhenti")
+			return
+		}
+	}
+}
+
+// poll mengklaim dan memproses jadwal jatuh tempo satu jenis sampai habis
+func (s *postgresSchedulerService) poll(ctx context.Context, kind string) error {
+	for {
+		now := s.clock.Now()
+
+		claimed, err := s.store.claim(ctx, kind, now, now.Add(s.conf.Lease), s.conf.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range claimed {
+			s.process(ctx, kind, c)
+		}
+
+		if len(claimed) < s.conf.BatchSize {
+			return nil
+		}
+	}
+}
+
+// process menjalankan satu jadwal. Jadwal yang gagal dibiarkan sehingga diklaim ulang setelah lease
+// habis, sampai MaxAttempts tercapai
+func (s *postgresSchedulerService) process(ctx context.Context, kind string, c claimedSchedule) {
+	// Attempts melewati MaxAttempts hanya jika klaim sebelumnya berakhir tanpa ack, misalnya replica
+	// mati di tengah handler. Jadwal dibuang agar handler yang selalu membuat replica mati tidak diulang terus
+	if c.Attempts > s.conf.MaxAttempts {
+		log.Printf("Jadwal %s task ID %d dibuang, %d klaim sebelumnya tidak selesai", kind, c.TaskID, c.Attempts-1)
+		s.ack(ctx, kind, c)
+		return
+	}
+
+	key := infra_scheduler.ScheduledKey{Kind: kind, TaskID: c.TaskID, Offset: time.Duration(c.OffsetSeconds) * time.Second}
+
+	err := infra_scheduler.Dispatch(s.handler, key)
+	if err == nil {
+		s.ack(ctx, kind, c)
+		return
+	}
+
+	if c.Attempts >= s.conf.MaxAttempts {
+		log.Printf("Jadwal %s task ID %d dibuang setelah %d percobaan: %+v", kind, c.TaskID, c.Attempts, err)
+		s.ack(ctx, kind, c)
+		return
+	}
+
+	log.Printf("Jadwal %s task ID %d gagal diproses (percobaan %d), dicoba lagi setelah lease habis: %+v", kind, c.TaskID, c.Attempts, err)
+}
+
+// ack menghapus jadwal yang selesai diproses
+func (s *postgresSchedulerService) ack(ctx context.Context, kind string, c claimedSchedule) {
+	if err := s.store.ack(ctx, kind, c); err != nil {
+		log.Println("Gagal menghapus jadwal task yang selesai:", err)
+	}
+}
+
+// claim mengklaim sampai limit jadwal yang jatuh tempo pada now dan mengganti waktunya dengan leaseUntil.
+// Jika hasil klaim gagal dibaca tidak ada jadwal yang diproses, jadwal yang sudah diklaim diambil ulang
+// setelah lease habis
+func (p pgStore) claim(ctx context.Context, kind string, now time.Time, leaseUntil time.Time, limit int) ([]claimedSchedule, error) {
+	query := ClaimSchedules
+	if kind == infra_scheduler.KindRemind {
+		query = ClaimReminders
+	}
+
+	rows, err := p.db.QueryxContext(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	claimed := []claimedSchedule{}
+	for rows.Next() {
+		var c claimedSchedule
+		if kind == infra_scheduler.KindExpire {
+			err = rows.Scan(&c.TaskID, &c.LeaseUntil, &c.Attempts)
+		} else {
+			err = rows.Scan(&c.TaskID, &c.OffsetSeconds, &c.LeaseUntil, &c.Attempts)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		claimed = append(claimed, c)
+	}
+
+	return claimed, rows.Err()
+}
+
+// ack menghapus jadwal hanya jika masih berisi batas lease klaim c
+func (p pgStore) ack(ctx context.Context, kind string, c claimedSchedule) error {
+	var err error
+	if kind == infra_scheduler.KindExpire {
+		_, err = p.db.ExecContext(ctx, AckSchedule, c.TaskID, c.LeaseUntil)
+	} else {
+		_, err = p.db.ExecContext(ctx, AckReminder, c.TaskID, c.OffsetSeconds, c.LeaseUntil)
+	}
+
+	return err
+}
+
+func (s *postgresSchedulerService) exec(query string, args ...interface{}) error {
+	_, err := s.db.Exec(query, args...)
+	if err != nil {
+		log.Println("Gagal menyimpan jadwal task:", err)
+		return err
+	}
+
+	return nil
+}
+
+// offsetSeconds mengubah offset reminder menjadi detik, sama dengan format key reminder di Redis
+func offsetSeconds(offsets []time.Duration) []int64 {
+	seconds := make([]int64, len(offsets))
+	for i, offset := range offsets {
+		seconds[i] = int64(offset / time.Second)
+	}
+
+	return seconds
+}
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+	"todo_list_consumer/src/infra/scheduler/schedulertest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRow meniru kolom scheduled_at dan schedule_attempts satu task
+type fakeRow struct {
+	at       time.Time
+	attempts int
+}
+
+// fakeStore meniru semantik query ClaimSchedules dan AckSchedule di memori
+type fakeStore struct {
+	rows map[int64]*fakeRow
+}
+
+func (f *fakeStore) claim(ctx context.Context, kind string, now time.Time, leaseUntil time.Time, limit int) ([]claimedSchedule, error) {
+	claimed := []claimedSchedule{}
+	for id, row := range f.rows {
+		if row.at.After(now) || len(claimed) == limit {
+			continue
+		}
+
+		row.at = leaseUntil
+		row.attempts++
+		claimed = append(claimed, claimedSchedule{TaskID: id, LeaseUntil: leaseUntil, Attempts: row.attempts})
+	}
+
+	return claimed, nil
+}
+
+func (f *fakeStore) ack(ctx context.Context, kind string, c claimedSchedule) error {
+	if row, ok := f.rows[c.TaskID]; ok && row.at.Equal(c.LeaseUntil) {
+		delete(f.rows, c.TaskID)
+	}
+
+	return nil
+}
+
+func newTestScheduler(t *testing.T) (*postgresSchedulerService, *fakeStore, *clock.Fake, *schedulertest.Handler) {
+	c := clock.NewFake(time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC))
+	store := &fakeStore{rows: map[int64]*fakeRow{}}
+	h := &schedulertest.Handler{}
+
+	s := &postgresSchedulerService{
+		store: store,
+		clock: c,
+		conf:  config.SchedulerConf{Lease: time.Minute, BatchSize: 10, MaxAttempts: 3},
+	}
+	s.RegisterHandler(h)
+
+	return s, store, c, h
+}
+
+func TestPollClaimsDueSchedulesOnce(t *testing.T) {
+	s, store, c, h := newTestScheduler(t)
+	store.rows[1] = &fakeRow{at: c.Now()}
+	store.rows[2] = &fakeRow{at: c.Now().Add(time.Hour)}
+
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Equal(t, []int64{1}, h.Expired())
+
+	// Jadwal yang sudah di-ack tidak diklaim lagi, jadwal yang belum jatuh tempo tetap tersimpan
+	c.Advance(time.Minute)
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Equal(t, []int64{1}, h.Expired())
+	assert.NotContains(t, store.rows, int64(1))
+	assert.Contains(t, store.rows, int64(2))
+}
+
+func TestCrashedClaimReclaimedAfterLease(t *testing.T) {
+	s, store, c, h := newTestScheduler(t)
+	store.rows[1] = &fakeRow{at: c.Now()}
+
+	// Replica mati setelah klaim sehingga jadwal tidak pernah di-ack
+	claimed, _ := store.claim(context.Background(), infra_scheduler.KindExpire, c.Now(), c.Now().Add(s.conf.Lease), 10)
+	assert.Len(t, claimed, 1)
+
+	// Selama lease belum habis replica lain tidak mengklaim jadwal yang sama
+	c.Advance(s.conf.Lease - time.Second)
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Empty(t, h.Expired())
+
+	c.Advance(time.Second)
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Equal(t, []int64{1}, h.Expired())
+	assert.Empty(t, store.rows)
+
+	// Ack terlambat dari klaim yang mati tidak berpengaruh
+	store.rows[1] = &fakeRow{at: c.Now().Add(time.Hour)}
+	assert.NoError(t, store.ack(context.Background(), infra_scheduler.KindExpire, claimed[0]))
+	assert.Contains(t, store.rows, int64(1))
+}
+
+func TestFailedScheduleRetriedUntilMaxAttempts(t *testing.T) {
+	s, store, c, h := newTestScheduler(t)
+	store.rows[1] = &fakeRow{at: c.Now()}
+	h.FailWith(errors.New("db down"), -1)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+		c.Advance(s.conf.Lease)
+	}
+
+	assert.Equal(t, []int64{1, 1, 1}, h.Expired())
+	assert.Empty(t, store.rows)
+}
+
+func TestFailedScheduleSucceedsOnRetry(t *testing.T) {
+	s, store, c, h := newTestScheduler(t)
+	store.rows[1] = &fakeRow{at: c.Now()}
+	h.FailWith(errors.New("db down"), 1)
+
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Contains(t, store.rows, int64(1))
+
+	c.Advance(s.conf.Lease)
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Equal(t, []int64{1, 1}, h.Expired())
+	assert.Empty(t, store.rows)
+}
+
+func TestCrashLoopScheduleDropped(t *testing.T) {
+	s, store, c, h := newTestScheduler(t)
+
+	// Tiga klaim sebelumnya berakhir tanpa ack
+	store.rows[1] = &fakeRow{at: c.Now(), attempts: 3}
+
+	assert.NoError(t, s.poll(context.Background(), infra_scheduler.KindExpire))
+	assert.Empty(t, h.Expired())
+	assert.Empty(t, store.rows)
+}
@@ -2,6 +2,8 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"time"
 	"todo_list_consumer/src/infra/config"
 
@@ -9,29 +11,71 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// NewRedisClient membuat koneksi ke Redis dan mengembalikan *redis.Client
-func NewRedisClient(conf config.RedisConf, logger *logrus.Logger) (*redis.Client, error) {
+// Topologi Redis yang didukung RedisConf.Mode
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// NewRedisClient membuat koneksi ke Redis sesuai topologi di konfigurasi. Hasilnya redis.UniversalClient:
+// *redis.Client untuk standalone dan sentinel (failover ke master baru otomatis), *redis.ClusterClient untuk cluster
+func NewRedisClient(conf config.RedisConf, logger *logrus.Logger) (redis.UniversalClient, error) {
 	ctx := context.Background()
 
-	// Membuat instance Redis client dengan konfigurasi dari file config
-	client := redis.NewClient(&redis.Options{
-		Addr:     conf.Host + ":" + conf.Port, // Alamat host dan port Redis
-		Password: "",                          // Password Redis (jika ada, kosong jika tidak di-set)
-		DB:       0,                           // Gunakan database default (biasanya 0)
+	addrs := conf.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{conf.Host + ":" + conf.Port} // Alamat host dan port Redis
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:    addrs,
+		Username: conf.Username, // User ACL Redis 6+, kosong berarti user default
+		Password: conf.Password, // Password Redis (kosong jika tidak di-set)
+		DB:       conf.DB,       // Index database, cluster hanya mendukung 0
+
+		SentinelUsername: conf.SentinelUsername,
+		SentinelPassword: conf.SentinelPassword,
 
 		// Konfigurasi Connection Pool
-		PoolSize:     conf.PoolSize,                   // Maksimum jumlah koneksi dalam pool
-		MinIdleConns: conf.MinIdleConns,               // Jumlah minimum koneksi idle
-		IdleTimeout:  time.Duration(conf.IdleTimeout), // Waktu maksimum koneksi idle sebelum ditutup
-	})
+		PoolSize:     conf.PoolSize,                                 // Maksimum jumlah koneksi dalam pool
+		MinIdleConns: conf.MinIdleConns,                             // Jumlah minimum koneksi idle
+		IdleTimeout:  time.Duration(conf.IdleTimeout) * time.Minute, // Waktu maksimum koneksi idle sebelum ditutup
+	}
+
+	if conf.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	var client redis.UniversalClient
+	switch conf.Mode {
+	case "", ModeStandalone:
+		client = redis.NewClient(opts.Simple())
+	case ModeSentinel:
+		if conf.MasterName == "" {
+			return nil, errors.New("REDIS_SENTINEL_MASTER is required in sentinel mode")
+		}
+
+		opts.MasterName = conf.MasterName
+		client = redis.NewFailoverClient(opts.Failover())
+	case ModeCluster:
+		if conf.DB != 0 {
+			return nil, errors.New("redis cluster only supports DB 0")
+		}
+
+		client = redis.NewClusterClient(opts.Cluster())
+	default:
+		return nil, errors.New("unknown REDIS_MODE " + conf.Mode)
+	}
 
 	// Melakukan ping untuk memastikan koneksi Redis berhasil
 	_, err := client.Ping(ctx).Result()
 	if err != nil {
 		logger.Errorf("Cannot connect to Redis: %s", err)
+		client.Close()
 		return nil, err // Jika gagal, kembalikan error agar bisa ditangani aplikasi
 	}
 
-	logger.Info("Connected to Redis successfully!") // Logging jika berhasil
+	logger.Infof("Connected to Redis (%s) successfully!", conf.Mode) // Logging jika berhasil
 	return client, nil
 }
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+)
+
+// expireKey membentuk key Redis untuk jadwal expire task
+func expireKey(taskID int64) string {
+	return infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindExpire, TaskID: taskID}.Member()
+}
+
+// remindKey membentuk key Redis untuk reminder sekian waktu sebelum expire
+func remindKey(taskID int64, offset time.Duration) string {
+	return infra_scheduler.ScheduledKey{Kind: infra_scheduler.KindRemind, TaskID: taskID, Offset: offset}.Member()
+}
+
+// remindersKey membentuk key set berisi member reminder task yang sedang terjadwal. Tidak dikenali
+// ParseKey sehingga expired-nya diabaikan worker
+func remindersKey(taskID int64) string {
+	return fmt.Sprintf("task:%d:reminders", taskID)
+}
+
+// claimKey membentuk key klaim untuk satu event jadwal. Prefix berbeda agar expired-nya claim key
+// tidak dikenali ParseKey sebagai jadwal. Deadline (unix milidetik) menjadi fencing token sehingga jadwal
+// yang dipasang ulang dengan deadline lain tetap bisa diklaim walau lease klaim sebelumnya belum habis
+func claimKey(key string, deadline int64) string {
+	return fmt.Sprintf("claim:%s:%d", key, deadline)
+}
+
+// deadlineKey menyimpan deadline jadwal yang sedang terpasang untuk key jadwal lengkap (sudah dengan
+// namespace). Key ini hidup sampai lease setelah jadwal expired agar replica yang menerima event bisa membaca
+// deadline untuk claim key. Key jadwal dibungkus hash tag sehingga di cluster berada di slot yang sama dengan
+// key jadwalnya dan keduanya bisa diubah secara atomik
+func deadlineKey(key string) string {
+	return "deadline:{" + key + "}"
+}
+
+// Namespace memisahkan key scheduler per prefix, environment dan tenant, contoh
+// todo:production:acme:task:42:expire. Key di luar namespace, misalnya milik environment lain atau
+// aplikasi lain yang berbagi Redis, tidak dikenali Parse sehingga event expired-nya diabaikan
+type Namespace struct {
+	prefix string // bagian namespace yang tidak kosong digabung dengan ":", kosong berarti tanpa namespace
+}
+
+// NewNamespace membentuk namespace dari konfigurasi scheduler
+func NewNamespace(conf config.SchedulerConf) Namespace {
+	parts := []string{}
+	for _, part := range []string{conf.KeyPrefix, conf.Environment, conf.Tenant} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return Namespace{prefix: strings.Join(parts, ":")}
+}
+
+// Key menambahkan namespace ke key jadwal atau key klaim
+func (n Namespace) Key(key string) string {
+	if n.prefix == "" {
+		return key
+	}
+
+	return n.prefix + ":" + key
+}
+
+// Parse mengenali key jadwal task di dalam namespace ini
+func (n Namespace) Parse(key string) (infra_scheduler.ScheduledKey, bool) {
+	if n.prefix != "" {
+		var ok bool
+		if key, ok = strings.CutPrefix(key, n.prefix+":"); !ok {
+			return infra_scheduler.ScheduledKey{}, false
+		}
+	}
+
+	return infra_scheduler.ParseKey(key)
+}
+
+// Pattern membentuk pattern SCAN untuk key di dalam namespace. Karakter glob di namespace di-escape
+// agar pattern tidak ikut mencocokkan namespace lain
+func (n Namespace) Pattern(pattern string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(n.prefix)
+	if escaped == "" {
+		return pattern
+	}
+
+	return escaped + ":" + pattern
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpireKey(t *testing.T) {
+	key, ok := infra_scheduler.ParseKey(expireKey(42))
+
+	if assert.True(t, ok) {
+		assert.Equal(t, infra_scheduler.KindExpire, key.Kind)
+		assert.Equal(t, int64(42), key.TaskID)
+	}
+}
+
+func TestParseRemindKey(t *testing.T) {
+	key, ok := infra_scheduler.ParseKey(remindKey(42, 10*time.Minute))
+
+	if assert.True(t, ok) {
+		assert.Equal(t, infra_scheduler.KindRemind, key.Kind)
+		assert.Equal(t, int64(42), key.TaskID)
+		assert.Equal(t, 10*time.Minute, key.Offset)
+	}
+}
+
+func TestParseUnknownKey(t *testing.T) {
+	for _, key := range []string{"session:1", "task:abc:expire", "task:1:expire:extra", "task:1:remind", claimKey(expireKey(1), 1741773600000), deadlineKey(expireKey(1)), deadlineKey(remindKey(1, time.Minute))} {
+		_, ok := infra_scheduler.ParseKey(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestNamespaceParseIgnoresOtherNamespaces(t *testing.T) {
+	ns := NewNamespace(config.SchedulerConf{KeyPrefix: "todo", Environment: "staging", Tenant: "acme"})
+
+	key, ok := ns.Parse(ns.Key(expireKey(42)))
+	if assert.True(t, ok) {
+		assert.Equal(t, "todo:staging:acme:task:42:expire", ns.Key(key.Member()))
+	}
+
+	for _, key := range []string{
+		expireKey(42),
+		"todo:production:acme:task:42:expire",
+		"todo:staging:other:task:42:expire",
+		ns.Key(claimKey(expireKey(42), 0)),
+		ns.Key(deadlineKey(expireKey(42))),
+	} {
+		_, ok := ns.Parse(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestNamespacePatternEscapesGlob(t *testing.T) {
+	ns := NewNamespace(config.SchedulerConf{KeyPrefix: "todo*", Tenant: "a[1]"})
+
+	assert.Equal(t, `todo\*:a\[1\]:task:*:expire`, ns.Pattern("task:*:expire"))
+	assert.Equal(t, "task:*:expire", Namespace{}.Pattern("task:*:expire"))
+}
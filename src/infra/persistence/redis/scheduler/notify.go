@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Mode pengecekan notify-keyspace-events saat startup
+const (
+	KeyspaceEventsConfigure = "configure" // aktifkan flag yang kurang dengan CONFIG SET
+	KeyspaceEventsVerify    = "verify"    // hanya cek, service menolak start jika flag kurang
+)
+
+// notifyKeyspaceEvents adalah parameter Redis yang mengatur keyspace notifications
+const notifyKeyspaceEvents = "notify-keyspace-events"
+
+// EnsureKeyspaceEvents memastikan Redis mengirim event __keyevent@*__:expired. Tanpa flag E dan x
+// (atau A yang mencakup x) key tetap expired tetapi worker tidak pernah menerima event-nya.
+// Dengan configure flag yang kurang ditambahkan, dengan verify dikembalikan error.
+// Di cluster pengecekan dijalankan di setiap master
+func EnsureKeyspaceEvents(ctx context.Context, client redis.UniversalClient, mode string) error {
+	return forEachMaster(ctx, client, func(ctx context.Context, node redis.UniversalClient) error {
+		return ensureNodeKeyspaceEvents(ctx, node, mode)
+	})
+}
+
+func ensureNodeKeyspaceEvents(ctx context.Context, node redis.UniversalClient, mode string) error {
+	current, err := getKeyspaceEvents(ctx, node)
+	if err != nil {
+		return err
+	}
+
+	missing := missingKeyspaceFlags(current)
+	if missing == "" {
+		return nil
+	}
+
+	if mode != KeyspaceEventsConfigure {
+		return fmt.Errorf("redis %s=%q tidak mengirim event expired, jalankan Redis dengan --notify-keyspace-events Ex "+
+			"atau set SCHEDULER_KEYSPACE_EVENTS=configure", notifyKeyspaceEvents, current)
+	}
+
+	// Flag lama dipertahankan agar subscriber lain tidak kehilangan event-nya
+	err = node.ConfigSet(ctx, notifyKeyspaceEvents, current+missing).Err()
+	if err != nil {
+		return fmt.Errorf("gagal mengaktifkan %s (CONFIG SET mungkin dinonaktifkan di Redis terkelola), "+
+			"aktifkan flag Ex secara manual: %w", notifyKeyspaceEvents, err)
+	}
+
+	log.Printf("Redis %s diubah dari %q menjadi %q", notifyKeyspaceEvents, current, current+missing)
+	return nil
+}
+
+// CheckKeyspaceEvents mengembalikan error jika Redis tidak mengirim event expired, dipakai readiness
+// check karena konfigurasi hilang saat Redis restart atau failover ke node tanpa flag di file konfigurasinya
+func CheckKeyspaceEvents(ctx context.Context, client redis.UniversalClient) error {
+	return forEachMaster(ctx, client, func(ctx context.Context, node redis.UniversalClient) error {
+		current, err := getKeyspaceEvents(ctx, node)
+		if err != nil {
+			return err
+		}
+
+		if missing := missingKeyspaceFlags(current); missing != "" {
+			return fmt.Errorf("%s=%q, flag %q tidak aktif", notifyKeyspaceEvents, current, missing)
+		}
+
+		return nil
+	})
+}
+
+func getKeyspaceEvents(ctx context.Context, node redis.UniversalClient) (string, error) {
+	result, err := node.ConfigGet(ctx, notifyKeyspaceEvents).Result()
+	if err != nil {
+		return "", fmt.Errorf("gagal membaca %s: %w", notifyKeyspaceEvents, err)
+	}
+
+	// Hasil CONFIG GET berupa pasangan nama dan nilai
+	if len(result) < 2 {
+		return "", fmt.Errorf("redis tidak mengembalikan %s", notifyKeyspaceEvents)
+	}
+
+	current, _ := result[1].(string)
+	return current, nil
+}
+
+// missingKeyspaceFlags mengembalikan flag yang perlu ditambahkan agar event expired dikirim
+func missingKeyspaceFlags(current string) string {
+	missing := ""
+	if !strings.Contains(current, "E") {
+		missing += "E"
+	}
+
+	if !strings.ContainsAny(current, "xA") {
+		missing += "x"
+	}
+
+	return missing
+}
@@ -0,0 +1,22 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingKeyspaceFlags(t *testing.T) {
+	cases := map[string]string{
+		"":     "Ex",
+		"Ex":   "",
+		"xE":   "",
+		"AKE":  "",
+		"Kx":   "E",
+		"KEg$": "x",
+	}
+
+	for current, want := range cases {
+		assert.Equal(t, want, missingKeyspaceFlags(current), current)
+	}
+}
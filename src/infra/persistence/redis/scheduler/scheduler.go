@@ -5,17 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
-	dto "todo_list_consumer/src/app/dto/task"
-	repo "todo_list_consumer/src/app/repositories/task"
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
 
 	"github.com/go-redis/redis/v8"
 )
 
-const RedisExpiredEvent = "__keyevent@*__:expired"
+// expiredEventChannel membentuk channel event expired untuk satu database Redis
+func expiredEventChannel(db int) string {
+	return fmt.Sprintf("__keyevent@%d__:expired", db)
+}
 
-// Event __keyevent@*__:expired adalah nama khusus yang digunakan oleh Redis untuk keyspace notifications.
+// Event __keyevent@<db>__:expired adalah nama khusus yang digunakan oleh Redis untuk keyspace notifications.
 // Ini adalah bagian dari mekanisme bawaan Redis untuk memberi tahu sistem lain saat suatu kunci (key)
 // di Redis telah kedaluwarsa (expired).
 
@@ -25,8 +30,9 @@ const RedisExpiredEvent = "__keyevent@*__:expired"
 // __keyevent@0__:
 // keyevent → Menandakan bahwa ini adalah event terkait perubahan pada suatu key.
 
-// @* → Menunjukkan bahwa event ini terjadi di database Redis dengan indeks *
-// (karena Redis bisa memiliki beberapa database, default-nya adalah 0)
+// @0 → Menunjukkan bahwa event ini terjadi di database Redis dengan indeks 0
+// (karena Redis bisa memiliki beberapa database, default-nya adalah 0). Worker hanya subscribe ke
+// database yang dipakai client, bukan @*, agar event dari database lain tidak ikut diproses
 
 // expired:
 // Menunjukkan bahwa event ini akan dipublikasikan ketika suatu key di Redis kedaluwarsa (karena TTL-nya habis).
@@ -34,45 +40,65 @@ const RedisExpiredEvent = "__keyevent@*__:expired"
 // Redis tidak memungkinkan kita untuk mengubah nama event ini karena ini adalah bagian dari
 // internal keyspace notifications yang sudah ditentukan oleh Redis sendiri.
 
-// Interface untuk scheduler booking
-type SchedulerInterface interface {
-	ScheduleTaskCancellation(taskID int64, expiresAt time.Time) error
-	StartWorker() // Memulai worker untuk mendengarkan event Redis
-}
-
 // Struct implementasi scheduler
 type bookingSchedulerService struct {
-	redisClient *redis.Client       // Redis client untuk menyimpan TTL booking
-	Repo        repo.TaskRepository // Repository untuk akses database booking
+	redisClient    redis.UniversalClient       // Redis client untuk menyimpan TTL booking (standalone, sentinel atau cluster)
+	handler        infra_scheduler.TaskHandler // Handler untuk memproses task yang expired
+	clock          clock.Clock                 // Sumber waktu untuk menghitung TTL
+	instanceID     string                      // Pemilik klaim event expired
+	lease          time.Duration               // Umur klaim event expired
+	keyspaceEvents string                      // Mode pengecekan notify-keyspace-events saat subscribe
+	ns             Namespace                   // Namespace key jadwal, key di luar namespace diabaikan
+	state          infra_scheduler.WorkerState // Status worker untuk health check
 }
 
-// Constructor untuk membuat service scheduler
-func NewBookingSchedulerService(redisClient *redis.Client, r repo.TaskRepository) SchedulerInterface {
+// extendScript memindahkan TTL key jadwal yang masih ada lalu menyimpan deadline barunya secara atomik.
+// Mengembalikan 0 jika key jadwal sudah tidak ada
+//
+// KEYS[1] = key jadwal, KEYS[2] = key deadline
+// ARGV[1] = TTL jadwal (ms), ARGV[2] = deadline (unix ms), ARGV[3] = TTL deadline (ms)
+var extendScript = redis.NewScript(`
+if redis.call('PEXPIRE', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// topologyCheckInterval adalah jeda pengecekan perubahan master cluster oleh worker keyspace
+const topologyCheckInterval = 30 * time.Second
+
+// Constructor untuk membuat service scheduler. TTL key dihitung dari clock, sedangkan waktu expired
+// dijalankan oleh Redis sehingga pergeseran clock hanya berlaku untuk jadwal yang dibuat setelahnya
+func NewBookingSchedulerService(redisClient redis.UniversalClient, c clock.Clock, conf config.SchedulerConf) infra_scheduler.SchedulerInterface {
 	return &bookingSchedulerService{
-		redisClient: redisClient,
-		Repo:        r,
+		redisClient:    redisClient,
+		clock:          c,
+		instanceID:     conf.InstanceID,
+		lease:          conf.Lease,
+		keyspaceEvents: conf.KeyspaceEvents,
+		ns:             NewNamespace(conf),
 	}
 }
 
+func (s *bookingSchedulerService) RegisterHandler(h infra_scheduler.TaskHandler) {
+	s.handler = h
+}
+
 func (s *bookingSchedulerService) ScheduleTaskCancellation(taskID int64, expiresAt time.Time) error {
 	ctx := context.Background()
-	key := fmt.Sprintf("task:%d:expire", taskID) // Format key unik untuk Redis
-
-	// Gunakan Asia/Jakarta jika waktu tidak memiliki zona waktu
-	loc, _ := time.LoadLocation("Asia/Jakarta")
-	expiresAt = time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(),
-		expiresAt.Hour(), expiresAt.Minute(), expiresAt.Second(), expiresAt.Nanosecond(), loc)
-
-	expiresAt = expiresAt.UTC() // Paksa expiresAt ke UTC
 
-	ttl := time.Until(expiresAt)
+	ttl := expiresAt.Sub(s.clock.Now())
 	if ttl <= 0 {
 		log.Println("Waktu kedaluwarsa sudah lewat, tidak bisa menjadwalkan pembatalan.")
 		return errors.New("expiration sudah lampau")
 	}
 
-	// Menyimpan key di Redis dengan TTL sekian waktu
-	err := s.redisClient.SetEX(ctx, key, taskID, ttl).Err()
+	// Menyimpan key di Redis dengan TTL sekian waktu. Key jadwal dan key deadline berada di slot yang
+	// sama sehingga keduanya dipasang dalam satu MULTI
+	pipe := s.redisClient.TxPipeline()
+	s.setSchedule(ctx, pipe, expireKey(taskID), taskID, expiresAt, ttl)
+	_, err := pipe.Exec(ctx)
 	if err != nil {
 		log.Println("Gagal menjadwalkan pembatalan task:", err)
 		return err
@@ -82,35 +108,391 @@ func (s *bookingSchedulerService) ScheduleTaskCancellation(taskID int64, expires
 	return nil
 }
 
-// Worker yang berjalan terus-menerus untuk mendengarkan event expiration dari Redis
-func (s *bookingSchedulerService) StartWorker() {
+// ExtendTaskCancellation memindahkan waktu expire key task yang sudah ada tanpa menghapus key-nya.
+// Jika key sudah tidak ada (misalnya hilang karena Redis di-flush), key dibuat ulang
+func (s *bookingSchedulerService) ExtendTaskCancellation(taskID int64, expiresAt time.Time) error {
 	ctx := context.Background()
-	pubsub := s.redisClient.PSubscribe(ctx, RedisExpiredEvent) // Subscribe ke event Redis expiration
+	key := s.ns.Key(expireKey(taskID))
 
-	log.Println("Worker Redis berjalan... Mendengarkan event expired")
+	ttl := expiresAt.Sub(s.clock.Now())
+	if ttl <= 0 {
+		return errors.New("expiration sudah lampau")
+	}
+
+	// TTL key jadwal dan deadline-nya diubah dalam satu script, jika tidak deadline yang tertinggal
+	// membuat klaim berikutnya memakai fencing token yang salah
+	extended, err := extendScript.Run(ctx, s.redisClient, []string{key, s.ns.Key(deadlineKey(key))},
+		ttl.Milliseconds(), expiresAt.UnixMilli(), (ttl + s.lease).Milliseconds()).Bool()
+	if err != nil {
+		log.Println("Gagal memperpanjang jadwal task:", err)
+		return err
+	}
+
+	if !extended {
+		log.Printf("Key %s tidak ditemukan, membuat jadwal baru", key)
+		return s.ScheduleTaskCancellation(taskID, expiresAt)
+	}
+
+	log.Printf("Jadwal task ID %d diperpanjang hingga %s", taskID, expiresAt.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// setSchedule memasang key jadwal beserta key deadline-nya. Key deadline tidak ikut dihapus saat jadwal
+// dibatalkan dan dibiarkan habis sendiri setelah lease
+func (s *bookingSchedulerService) setSchedule(ctx context.Context, pipe redis.Pipeliner, member string, taskID int64, at time.Time, ttl time.Duration) {
+	pipe.SetEX(ctx, s.ns.Key(member), taskID, ttl)
+	pipe.SetEX(ctx, s.ns.Key(deadlineKey(s.ns.Key(member))), at.UnixMilli(), ttl+s.lease)
+}
+
+// CancelTaskCancellation menghapus jadwal expire task, misalnya karena task sudah dihapus
+func (s *bookingSchedulerService) CancelTaskCancellation(taskID int64) error {
+	ctx := context.Background()
+	key := s.ns.Key(expireKey(taskID))
+
+	err := s.redisClient.Del(ctx, key).Err()
+	if err != nil {
+		log.Println("Gagal menghapus jadwal task:", err)
+		return err
+	}
+
+	return nil
+}
+
+// ScheduleTaskReminders membuat satu key per offset, contoh offset 10m berarti reminder
+// dikirim 10 menit sebelum expiresAt. Offset yang waktunya sudah lewat dilewati
+func (s *bookingSchedulerService) ScheduleTaskReminders(taskID int64, expiresAt time.Time, offsets []time.Duration) error {
+	ctx := context.Background()
+	now := s.clock.Now()
+
+	// Member setiap reminder dicatat di index agar bisa dihapus walaupun offset di config sudah berubah.
+	// Index habis bersama deadline karena semua reminder jatuh tempo sebelumnya
+	index := s.ns.Key(remindersKey(taskID))
+	pipe := s.redisClient.TxPipeline()
+	for _, offset := range offsets {
+		ttl := expiresAt.Add(-offset).Sub(now)
+		if ttl <= 0 {
+			continue
+		}
+
+		member := remindKey(taskID, offset)
+		s.setSchedule(ctx, pipe, member, taskID, expiresAt.Add(-offset), ttl)
+		pipe.SAdd(ctx, index, member)
+		pipe.PExpire(ctx, index, expiresAt.Sub(now))
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Println("Gagal menjadwalkan reminder task:", err)
+		return err
+	}
+
+	return nil
+}
+
+// CancelTaskReminders menghapus semua key reminder task yang tercatat di index-nya
+func (s *bookingSchedulerService) CancelTaskReminders(taskID int64) error {
+	ctx := context.Background()
+	index := s.ns.Key(remindersKey(taskID))
+
+	members, err := s.redisClient.SMembers(ctx, index).Result()
+	if err != nil {
+		log.Println("Gagal membaca reminder task:", err)
+		return err
+	}
+
+	// DEL per key karena di cluster key reminder bisa berada di slot berbeda
+	pipe := s.redisClient.Pipeline()
+	for _, member := range members {
+		pipe.Del(ctx, s.ns.Key(member))
+	}
+	pipe.Del(ctx, index)
+
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Println("Gagal menghapus reminder task:", err)
+		return err
+	}
+
+	return nil
+}
+
+// ScheduledTaskIDs memindai key expire task dengan SCAN agar Redis tidak terblokir seperti KEYS.
+// Di cluster SCAN dijalankan di setiap master
+func (s *bookingSchedulerService) ScheduledTaskIDs() ([]int64, error) {
+	return s.scanTaskIDs("task:*:expire", infra_scheduler.KindExpire)
+}
+
+// ReminderTaskIDs memindai key reminder task, satu task bisa memiliki beberapa key reminder
+func (s *bookingSchedulerService) ReminderTaskIDs() ([]int64, error) {
+	return s.scanTaskIDs("task:*:remind:*", infra_scheduler.KindRemind)
+}
+
+// scanTaskIDs mengumpulkan ID task unik dari key berjenis kind yang cocok dengan pattern
+func (s *bookingSchedulerService) scanTaskIDs(pattern string, kind string) ([]int64, error) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	seen := map[int64]bool{}
+	ids := []int64{}
+
+	err := forEachMaster(ctx, s.redisClient, func(ctx context.Context, node redis.UniversalClient) error {
+		iter := node.Scan(ctx, 0, s.ns.Pattern(pattern), 1000).Iterator()
+		for iter.Next(ctx) {
+			key, ok := s.ns.Parse(iter.Val())
+			if ok && key.Kind == kind {
+				mu.Lock()
+				if !seen[key.TaskID] {
+					seen[key.TaskID] = true
+					ids = append(ids, key.TaskID)
+				}
+				mu.Unlock()
+			}
+		}
+
+		return iter.Err()
+	})
+	if err != nil {
+		log.Println("Gagal membaca jadwal task:", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s *bookingSchedulerService) Status() infra_scheduler.WorkerStatus {
+	return s.state.Get()
+}
+
+// Worker yang mendengarkan event expiration dari Redis sampai ctx dibatalkan. Jika koneksi putus,
+// worker subscribe ulang dengan jeda yang makin panjang agar tidak membanjiri log saat Redis mati
+func (s *bookingSchedulerService) StartWorker(ctx context.Context) {
+	defer s.state.Set(infra_scheduler.WorkerStopped)
+
+	backoff := &infra_scheduler.Backoff{Min: time.Second, Max: 30 * time.Second}
+	for {
+		err := s.listen(ctx, backoff)
+		if ctx.Err() != nil {
+			log.Println("Worker Redis berhenti")
+			return
+		}
+
+		s.state.Set(infra_scheduler.WorkerReconnecting)
+		wait := backoff.Next()
+		log.Printf("Error menerima pesan Redis, subscribe ulang dalam %s: %v", wait, err)
+
+		if !infra_scheduler.Sleep(ctx, wait) {
+			log.Println("Worker Redis berhenti")
+			return
+		}
+	}
+}
+
+// listen subscribe ke event expired di setiap master dan memproses pesan sampai terjadi error, master
+// cluster berubah atau ctx dibatalkan. Dengan mode configure flag notify-keyspace-events dipasang ulang
+// sebelum subscribe karena hilang saat Redis restart atau failover ke node lain
+func (s *bookingSchedulerService) listen(ctx context.Context, backoff *infra_scheduler.Backoff) error {
+	if s.keyspaceEvents == KeyspaceEventsConfigure {
+		if err := EnsureKeyspaceEvents(ctx, s.redisClient, s.keyspaceEvents); err != nil {
+			return err
+		}
+	}
+
+	topology, err := masterTopology(ctx, s.redisClient)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := masterNodes(ctx, s.redisClient)
+	if err != nil {
+		return err
+	}
+
+	// Membatalkan listenCtx menghentikan subscription node lain saat satu node gagal
+	listenCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(nodes))
+	subscribed := make(chan struct{}, len(nodes))
+	for _, node := range nodes {
+		go func(node redis.UniversalClient) {
+			errs <- s.subscribe(listenCtx, node, subscribed)
+		}(node)
+	}
+
+	for range nodes {
+		select {
+		case <-subscribed:
+		case err := <-errs:
+			return err
+		}
+	}
+
+	s.state.Set(infra_scheduler.WorkerSubscribed)
+	backoff.Reset()
+	log.Printf("Worker Redis berjalan... Mendengarkan event expired di %d node", len(nodes))
+
+	if topology == "" {
+		return <-errs
+	}
+
+	ticker := time.NewTicker(topologyCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-errs:
+			return err
+		case <-ticker.C:
+			current, err := masterTopology(ctx, s.redisClient)
+			if err != nil {
+				return err
+			}
+
+			if current != topology {
+				return fmt.Errorf("master cluster berubah dari %s menjadi %s", topology, current)
+			}
+		}
+	}
+}
+
+// subscribe mendengarkan event expired dari satu node. subscribed dikirim setelah Redis
+// mengonfirmasi subscription
+func (s *bookingSchedulerService) subscribe(ctx context.Context, node redis.UniversalClient, subscribed chan<- struct{}) error {
+	// Subscribe ke event Redis expiration di database yang dipakai client, node cluster selalu database 0
+	db := 0
+	if client, ok := node.(*redis.Client); ok {
+		db = client.Options().DB
+	}
+
+	pubsub := node.Subscribe(ctx, expiredEventChannel(db))
+	defer pubsub.Close()
+
+	// ReceiveMessage tidak berhenti saat ctx dibatalkan, menutup pubsub membuatnya kembali dengan error
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			pubsub.Close()
+		case <-done:
+		}
+	}()
+
+	// Pesan pertama adalah konfirmasi subscribe
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+	subscribed <- struct{}{}
 
 	for {
 		// Menerima pesan dari Redis ketika ada key yang expired
 		msg, err := pubsub.ReceiveMessage(ctx)
 		if err != nil {
-			log.Println("Error menerima pesan Redis:", err)
-			continue
+			return err
 		}
 
-		// Mengambil Task ID dari key yang expired
-		var data dto.ExpireTaskReqDTO
-		_, err = fmt.Sscanf(msg.Payload, "task:%d:expire", &data.ID)
-		if err != nil {
-			continue
-		}
+		s.handleExpired(ctx, msg.Payload)
+	}
+}
 
-		// Membatalkan booking karena tidak dibayar dalam waktu yang ditentukan
-		log.Printf("Membatalkan task ID %d karena tidak diselesaikan", data.ID)
-		err = s.Repo.ExpireTask(&data)
-		if err != nil {
-			log.Println("Gagal membatalkan task:", err)
-		} else {
-			log.Printf("Task ID %d berhasil dibatalkan", data.ID)
+// handleExpired memproses satu event expired. Event dikirim ke semua replica yang subscribe,
+// hanya replica yang berhasil membuat claim key yang meneruskannya ke handler
+func (s *bookingSchedulerService) handleExpired(ctx context.Context, payload string) {
+	// Mengambil Task ID dari key yang expired, key dari namespace lain diabaikan
+	key, ok := s.ns.Parse(payload)
+	if !ok {
+		return
+	}
+
+	claimed, err := s.claim(ctx, key)
+	if err != nil {
+		// Tanpa klaim event tidak diproses, reconciler akan menangani deadline yang terlewat
+		log.Println("Gagal mengklaim event expired:", err)
+		return
+	}
+
+	if !claimed {
+		return
+	}
+
+	s.dispatch(key)
+}
+
+// claim membuat claim key dengan SET NX. Claim tidak dihapus setelah diproses dan dibiarkan habis
+// setelah lease, agar replica yang menerima event lebih lambat tetap kalah. Deadline jadwal ikut di claim
+// key sehingga jadwal yang dipasang ulang di dalam lease (misalnya masa tenggang) tetap diproses.
+// Jadwal tanpa key deadline (dibuat sebelum fencing token ada) memakai deadline 0
+func (s *bookingSchedulerService) claim(ctx context.Context, key infra_scheduler.ScheduledKey) (bool, error) {
+	deadline, err := s.redisClient.Get(ctx, s.ns.Key(deadlineKey(s.ns.Key(key.Member())))).Int64()
+	if err != nil && err != redis.Nil {
+		return false, err
+	}
+
+	return s.redisClient.SetNX(ctx, s.ns.Key(claimKey(key.Member(), deadline)), s.instanceID, s.lease).Result()
+}
+
+// dispatch meneruskan key yang expired ke handler sesuai jenis jadwalnya
+func (s *bookingSchedulerService) dispatch(key infra_scheduler.ScheduledKey) {
+	if err := infra_scheduler.Dispatch(s.handler, key); err != nil {
+		log.Println("Gagal memproses jadwal task:", err)
+	}
+}
+
+// ScheduledExpiry menghitung waktu jadwal expire dari sisa TTL key
+func (s *bookingSchedulerService) ScheduledExpiry(taskID int64) (time.Time, bool, error) {
+	ctx := context.Background()
+
+	ttl, err := s.redisClient.PTTL(ctx, s.ns.Key(expireKey(taskID))).Result()
+	if err != nil {
+		log.Println("Gagal membaca jadwal task:", err)
+		return time.Time{}, false, err
+	}
+
+	// TTL negatif berarti key tidak ada atau tidak memiliki expiry
+	if ttl < 0 {
+		return time.Time{}, false, nil
+	}
+
+	return s.clock.Now().Add(ttl), true, nil
+}
+
+// UpcomingExpirations memindai semua key expire di namespace lalu membaca TTL-nya. Redis tidak mengurutkan
+// key berdasarkan TTL sehingga biayanya sebanding dengan jumlah jadwal, hanya untuk endpoint admin
+func (s *bookingSchedulerService) UpcomingExpirations(from time.Time, to time.Time, limit int) ([]infra_scheduler.ScheduledExpiry, error) {
+	ctx := context.Background()
+	now := s.clock.Now()
+
+	var mu sync.Mutex
+	expirations := []infra_scheduler.ScheduledExpiry{}
+
+	err := forEachMaster(ctx, s.redisClient, func(ctx context.Context, node redis.UniversalClient) error {
+		iter := node.Scan(ctx, 0, s.ns.Pattern("task:*:expire"), 1000).Iterator()
+		for iter.Next(ctx) {
+			key, ok := s.ns.Parse(iter.Val())
+			if !ok || key.Kind != infra_scheduler.KindExpire {
+				continue
+			}
+
+			ttl, err := node.PTTL(ctx, iter.Val()).Result()
+			if err != nil {
+				return err
+			}
+
+			at := now.Add(ttl)
+			if ttl < 0 || at.Before(from) || at.After(to) {
+				continue
+			}
+
+			mu.Lock()
+			expirations = append(expirations, infra_scheduler.ScheduledExpiry{TaskID: key.TaskID, ExpiresAt: at})
+			mu.Unlock()
 		}
+
+		return iter.Err()
+	})
+	if err != nil {
+		log.Println("Gagal membaca jadwal task:", err)
+		return nil, err
 	}
+
+	return infra_scheduler.SortExpirations(expirations, limit), nil
 }
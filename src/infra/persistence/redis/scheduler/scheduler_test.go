@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+	"todo_list_consumer/src/infra/scheduler/schedulertest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestReplicas membuat beberapa scheduler keyspace yang berbagi satu Redis dan satu handler,
+// seperti beberapa pod yang menerima event expired yang sama
+func newTestReplicas(t *testing.T, n int, h infra_scheduler.TaskHandler) ([]*bookingSchedulerService, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	replicas := make([]*bookingSchedulerService, n)
+	for i := range replicas {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		replicas[i] = NewBookingSchedulerService(client, clock.New(), config.SchedulerConf{
+			InstanceID: fmt.Sprintf("pod-%d", i),
+			Lease:      30 * time.Second,
+		}).(*bookingSchedulerService)
+		replicas[i].RegisterHandler(h)
+	}
+
+	return replicas, mr
+}
+
+func TestExpiredEventProcessedByOneReplica(t *testing.T) {
+	h := &schedulertest.Handler{}
+	replicas, mr := newTestReplicas(t, 3, h)
+
+	var wg sync.WaitGroup
+	for _, s := range replicas {
+		wg.Add(1)
+		go func(s *bookingSchedulerService) {
+			defer wg.Done()
+			s.handleExpired(context.Background(), expireKey(1))
+			s.handleExpired(context.Background(), expireKey(2))
+		}(s)
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(t, []int64{1, 2}, h.Expired())
+	assert.True(t, mr.Exists(claimKey(expireKey(1), 0)))
+}
+
+func TestExpiredEventProcessedAgainAfterLease(t *testing.T) {
+	h := &schedulertest.Handler{}
+	replicas, mr := newTestReplicas(t, 2, h)
+
+	replicas[0].handleExpired(context.Background(), expireKey(1))
+	replicas[1].handleExpired(context.Background(), expireKey(1))
+	assert.Equal(t, []int64{1}, h.Expired())
+
+	// Jadwal yang sama dipasang lagi (misalnya masa tenggang) dan expired setelah lease habis
+	mr.FastForward(30 * time.Second)
+	replicas[1].handleExpired(context.Background(), expireKey(1))
+	assert.Equal(t, []int64{1, 1}, h.Expired())
+}
+
+func TestRescheduledKeyProcessedAgainWithinLease(t *testing.T) {
+	h := &schedulertest.Handler{}
+	replicas, mr := newTestReplicas(t, 2, h)
+	deadline := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+
+	assert.NoError(t, replicas[0].ScheduleTaskCancellation(1, deadline))
+	assert.NoError(t, replicas[0].ScheduleTaskReminders(1, deadline, []time.Duration{10 * time.Second}))
+	for _, s := range replicas {
+		s.handleExpired(context.Background(), expireKey(1))
+		s.handleExpired(context.Background(), remindKey(1, 10*time.Second))
+	}
+	assert.Equal(t, []int64{1}, h.Expired())
+	assert.Len(t, h.Metas(), 2)
+	assert.True(t, mr.Exists(claimKey(expireKey(1), deadline.UnixMilli())))
+
+	// Masa tenggang memasang ulang key yang sama beberapa detik kemudian, jauh sebelum lease 30 detik habis
+	grace := deadline.Add(5 * time.Second)
+	assert.NoError(t, replicas[1].ExtendTaskCancellation(1, grace))
+	stored, _ := mr.Get(deadlineKey(expireKey(1)))
+	assert.Equal(t, strconv.FormatInt(grace.UnixMilli(), 10), stored)
+	for _, s := range replicas {
+		s.handleExpired(context.Background(), expireKey(1))
+	}
+	assert.Equal(t, []int64{1, 1}, h.Expired())
+	assert.True(t, mr.Exists(claimKey(expireKey(1), grace.UnixMilli())))
+
+	// Key yang hilang lalu dibuat ulang lewat ExtendTaskCancellation juga mendapat deadline baru
+	mr.Del(expireKey(1))
+	again := grace.Add(5 * time.Second)
+	assert.NoError(t, replicas[1].ExtendTaskCancellation(1, again))
+	replicas[0].handleExpired(context.Background(), expireKey(1))
+	assert.Equal(t, []int64{1, 1, 1}, h.Expired())
+}
+
+func TestExpiredEventIgnoresUnknownKey(t *testing.T) {
+	h := &schedulertest.Handler{}
+	replicas, mr := newTestReplicas(t, 1, h)
+
+	replicas[0].handleExpired(context.Background(), "session:1")
+
+	assert.Empty(t, h.Expired())
+	assert.False(t, mr.Exists(claimKey("session:1", 0)))
+}
+
+func TestWorkerSubscribesAndStopsWithContext(t *testing.T) {
+	h := &schedulertest.Handler{}
+	replicas, mr := newTestReplicas(t, 1, h)
+	s := replicas[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.StartWorker(ctx)
+		close(stopped)
+	}()
+
+	assert.Eventually(t, func() bool { return s.Status() == infra_scheduler.WorkerSubscribed }, time.Second, 10*time.Millisecond)
+
+	mr.Publish("__keyevent@0__:expired", expireKey(1))
+	assert.Eventually(t, func() bool { return len(h.Expired()) == 1 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+	assert.Equal(t, infra_scheduler.WorkerStopped, s.Status())
+}
+
+func TestWorkerIgnoresOtherNamespacesAndDatabases(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), DB: 1})
+	t.Cleanup(func() { client.Close() })
+
+	h := &schedulertest.Handler{}
+	s := NewBookingSchedulerService(client, clock.New(), config.SchedulerConf{
+		InstanceID:  "pod-0",
+		Lease:       30 * time.Second,
+		KeyPrefix:   "todo",
+		Environment: "staging",
+		Tenant:      "acme",
+	}).(*bookingSchedulerService)
+	s.RegisterHandler(h)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.StartWorker(ctx)
+
+	assert.Eventually(t, func() bool { return s.Status() == infra_scheduler.WorkerSubscribed }, time.Second, 10*time.Millisecond)
+
+	// Database lain dan namespace lain diabaikan
+	mr.Publish("__keyevent@0__:expired", "todo:staging:acme:task:1:expire")
+	mr.Publish("__keyevent@1__:expired", "todo:production:acme:task:2:expire")
+	mr.Publish("__keyevent@1__:expired", expireKey(3))
+	mr.Publish("__keyevent@1__:expired", "todo:staging:acme:task:4:expire")
+
+	assert.Eventually(t, func() bool { return len(h.Expired()) == 1 }, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []int64{4}, h.Expired())
+}
+
+func TestKeyspaceScheduledExpiryFromTTL(t *testing.T) {
+	replicas, _ := newTestReplicas(t, 1, &schedulertest.Handler{})
+	s := replicas[0]
+
+	c := clock.NewFake(time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC))
+	s.clock = c
+
+	assert.NoError(t, s.ScheduleTaskCancellation(1, c.Now().Add(time.Hour)))
+	assert.NoError(t, s.ScheduleTaskCancellation(2, c.Now().Add(10*time.Minute)))
+	assert.NoError(t, s.ScheduleTaskCancellation(3, c.Now().Add(3*time.Hour)))
+
+	at, ok, err := s.ScheduledExpiry(1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, c.Now().Add(time.Hour), at)
+
+	_, ok, _ = s.ScheduledExpiry(4)
+	assert.False(t, ok)
+
+	upcoming, err := s.UpcomingExpirations(c.Now(), c.Now().Add(2*time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []infra_scheduler.ScheduledExpiry{
+		{TaskID: 2, ExpiresAt: c.Now().Add(10 * time.Minute)},
+		{TaskID: 1, ExpiresAt: c.Now().Add(time.Hour)},
+	}, upcoming)
+}
+
+func TestKeyspaceCancelRemindersFromOldOffsets(t *testing.T) {
+	replicas, mr := newTestReplicas(t, 1, &schedulertest.Handler{})
+	s := replicas[0]
+	deadline := time.Now().Add(3 * time.Hour)
+
+	// Reminder dibuat dengan offset lama, lalu config berubah sebelum task selesai
+	assert.NoError(t, s.ScheduleTaskReminders(1, deadline, []time.Duration{time.Hour, 2 * time.Hour}))
+	assert.NoError(t, s.ScheduleTaskReminders(2, deadline, []time.Duration{time.Hour}))
+
+	ids, err := s.ReminderTaskIDs()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2}, ids)
+
+	assert.NoError(t, s.CancelTaskReminders(1))
+	ids, _ = s.ReminderTaskIDs()
+	assert.Equal(t, []int64{2}, ids)
+	assert.False(t, mr.Exists(remindKey(1, time.Hour)))
+	assert.False(t, mr.Exists(remindKey(1, 2*time.Hour)))
+	assert.False(t, mr.Exists(remindersKey(1)))
+	assert.True(t, mr.Exists(remindKey(2, time.Hour)))
+}
+
+func TestDispatchSetsMessageID(t *testing.T) {
+	h := &schedulertest.Handler{}
+	keys := []infra_scheduler.ScheduledKey{
+		{Kind: infra_scheduler.KindExpire, TaskID: 1},
+		{Kind: infra_scheduler.KindRemind, TaskID: 1, Offset: time.Hour},
+	}
+
+	for _, key := range keys {
+		assert.NoError(t, infra_scheduler.Dispatch(h, key))
+	}
+
+	assert.Equal(t, []dto.EventMetaDTO{
+		{Source: taskConst.SOURCE_SCHEDULER, MessageID: "task:1:expire"},
+		{Source: taskConst.SOURCE_SCHEDULER, MessageID: "task:1:remind:3600"},
+	}, h.Metas())
+}
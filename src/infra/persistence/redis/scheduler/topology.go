@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Di Redis Cluster keyspace notification, CONFIG dan SCAN hanya berlaku untuk node yang menerimanya,
+// sehingga harus dijalankan di setiap master. Standalone dan sentinel cukup memakai client itu sendiri
+// karena client sentinel selalu terhubung ke master yang aktif
+
+// forEachMaster menjalankan fn di setiap master, bersamaan untuk cluster
+func forEachMaster(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, node redis.UniversalClient) error) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return fn(ctx, node)
+		})
+	}
+
+	return fn(ctx, client)
+}
+
+// masterNodes mengembalikan client setiap master yang harus di-subscribe
+func masterNodes(ctx context.Context, client redis.UniversalClient) ([]redis.UniversalClient, error) {
+	var mu sync.Mutex
+	nodes := []redis.UniversalClient{}
+
+	err := forEachMaster(ctx, client, func(ctx context.Context, node redis.UniversalClient) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// masterTopology mengembalikan alamat master cluster yang diurutkan, kosong untuk topologi lain.
+// Dipakai worker keyspace untuk subscribe ulang saat master cluster berubah
+func masterTopology(ctx context.Context, client redis.UniversalClient) (string, error) {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return "", nil
+	}
+
+	var mu sync.Mutex
+	addrs := []string{}
+
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		addrs = append(addrs, node.Options().Addr)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(addrs)
+	return strings.Join(addrs, ","), nil
+}
@@ -0,0 +1,375 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// zsetKeys adalah key Redis yang dipakai backend zset. Hash tag {<namespace>:task:schedule} menempatkan
+// semuanya di slot yang sama agar claimScript dan transaksi ack bisa berjalan di Redis Cluster.
+// Member di dalamnya tidak memakai namespace karena key-nya sudah terpisah per namespace
+type zsetKeys struct {
+	schedule   string // jadwal menunggu, score = waktu jatuh tempo (unix ms)
+	processing string // jadwal yang sedang diproses, score = batas lease (unix ms)
+	attempts   string // hash jumlah percobaan per jadwal yang gagal
+	tag        string // hash tag, dipakai untuk membentuk key index reminder per task
+}
+
+// reminders membentuk key set berisi member reminder satu task
+func (k zsetKeys) reminders(taskID int64) string {
+	return k.tag + ":" + remindersKey(taskID)
+}
+
+// newZSetKeys membentuk key zset untuk namespace ns. Jadwal di key lama tanpa hash tag atau namespace
+// tidak dipindahkan karena di cluster key lama berada di slot lain, jadwal expire dibuat ulang reconciler
+func newZSetKeys(ns Namespace) zsetKeys {
+	tag := "{" + ns.Key("task:schedule") + "}"
+	return zsetKeys{
+		schedule:   tag,
+		processing: tag + ":processing",
+		attempts:   tag + ":attempts",
+		tag:        tag,
+	}
+}
+
+// claimScript mengembalikan jadwal yang lease-nya habis ke antrian, lalu memindahkan jadwal yang jatuh
+// tempo ke set processing secara atomik. Replica lain tidak akan mengklaim jadwal yang sama
+//
+// KEYS[1] = schedule, KEYS[2] = processing
+// ARGV[1] = sekarang (ms), ARGV[2] = jumlah maksimum, ARGV[3] = batas lease (ms)
+var claimScript = redis.NewScript(`
+local stale = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, member in ipairs(stale) do
+	redis.call('ZREM', KEYS[2], member)
+	redis.call('ZADD', KEYS[1], 'NX', ARGV[1], member)
+end
+
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, member in ipairs(due) do
+	redis.call('ZREM', KEYS[1], member)
+	redis.call('ZADD', KEYS[2], ARGV[3], member)
+end
+return due
+`)
+
+// zsetSchedulerService menyimpan jadwal di sorted set Redis dan mem-polling jadwal yang jatuh tempo.
+// Berbeda dengan keyspace notification, jadwal tidak hilang saat worker terputus atau pod mati:
+// jadwal yang terlewat tetap diproses saat worker berjalan lagi
+type zsetSchedulerService struct {
+	redisClient redis.UniversalClient
+	handler     infra_scheduler.TaskHandler
+	clock       clock.Clock
+	conf        config.SchedulerConf
+	keys        zsetKeys
+	state       infra_scheduler.WorkerState
+}
+
+// NewZSetSchedulerService membuat scheduler berbasis sorted set Redis
+func NewZSetSchedulerService(redisClient redis.UniversalClient, c clock.Clock, conf config.SchedulerConf) infra_scheduler.SchedulerInterface {
+	return &zsetSchedulerService{
+		redisClient: redisClient,
+		clock:       c,
+		conf:        conf,
+		keys:        newZSetKeys(NewNamespace(conf)),
+	}
+}
+
+func (s *zsetSchedulerService) RegisterHandler(h infra_scheduler.TaskHandler) {
+	s.handler = h
+}
+
+func (s *zsetSchedulerService) ScheduleTaskCancellation(taskID int64, expiresAt time.Time) error {
+	if !expiresAt.After(s.clock.Now()) {
+		log.Println("Waktu kedaluwarsa sudah lewat, tidak bisa menjadwalkan pembatalan.")
+		return errors.New("expiration sudah lampau")
+	}
+
+	return s.add(expiresAt, expireKey(taskID))
+}
+
+// ExtendTaskCancellation cukup memperbarui score jadwal, ZADD membuat jadwal baru jika belum ada
+func (s *zsetSchedulerService) ExtendTaskCancellation(taskID int64, expiresAt time.Time) error {
+	if !expiresAt.After(s.clock.Now()) {
+		return errors.New("expiration sudah lampau")
+	}
+
+	return s.add(expiresAt, expireKey(taskID))
+}
+
+func (s *zsetSchedulerService) CancelTaskCancellation(taskID int64) error {
+	return s.remove(expireKey(taskID))
+}
+
+func (s *zsetSchedulerService) ScheduleTaskReminders(taskID int64, expiresAt time.Time, offsets []time.Duration) error {
+	now := s.clock.Now()
+	members := []*redis.Z{}
+	names := []interface{}{}
+	for _, offset := range offsets {
+		at := expiresAt.Add(-offset)
+		if !at.After(now) {
+			continue
+		}
+
+		member := remindKey(taskID, offset)
+		members = append(members, &redis.Z{Score: score(at), Member: member})
+		names = append(names, member)
+	}
+
+	if len(members) == 0 {
+		return nil
+	}
+
+	// Index reminder per task dipakai CancelTaskReminders, habis bersama deadline task
+	ctx := context.Background()
+	index := s.keys.reminders(taskID)
+	pipe := s.redisClient.TxPipeline()
+	pipe.ZAdd(ctx, s.keys.schedule, members...)
+	pipe.SAdd(ctx, index, names...)
+	pipe.PExpire(ctx, index, expiresAt.Sub(now))
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Println("Gagal menjadwalkan reminder task:", err)
+		return err
+	}
+
+	return nil
+}
+
+// CancelTaskReminders menghapus semua reminder task yang tercatat di index-nya
+func (s *zsetSchedulerService) CancelTaskReminders(taskID int64) error {
+	ctx := context.Background()
+	index := s.keys.reminders(taskID)
+
+	members, err := s.redisClient.SMembers(ctx, index).Result()
+	if err != nil {
+		log.Println("Gagal membaca reminder task:", err)
+		return err
+	}
+
+	if len(members) > 0 {
+		if err := s.remove(members...); err != nil {
+			return err
+		}
+	}
+
+	return s.redisClient.Del(ctx, index).Err()
+}
+
+// ScheduledTaskIDs membaca jadwal expire dari antrian maupun yang sedang diproses
+func (s *zsetSchedulerService) ScheduledTaskIDs() ([]int64, error) {
+	return s.taskIDs(infra_scheduler.KindExpire)
+}
+
+// ReminderTaskIDs mengembalikan ID task yang masih memiliki member reminder di sorted set
+func (s *zsetSchedulerService) ReminderTaskIDs() ([]int64, error) {
+	return s.taskIDs(infra_scheduler.KindRemind)
+}
+
+// taskIDs mengumpulkan ID task unik dari member berjenis kind, termasuk yang sedang diklaim worker
+func (s *zsetSchedulerService) taskIDs(kind string) ([]int64, error) {
+	ctx := context.Background()
+	seen := map[int64]bool{}
+	ids := []int64{}
+
+	for _, set := range []string{s.keys.schedule, s.keys.processing} {
+		members, err := s.redisClient.ZRange(ctx, set, 0, -1).Result()
+		if err != nil {
+			log.Println("Gagal membaca jadwal task:", err)
+			return nil, err
+		}
+
+		for _, member := range members {
+			key, ok := infra_scheduler.ParseKey(member)
+			if ok && key.Kind == kind && !seen[key.TaskID] {
+				seen[key.TaskID] = true
+				ids = append(ids, key.TaskID)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// ScheduledExpiry membaca score jadwal expire. Jadwal yang sedang diklaim worker dianggap tidak terjadwal
+func (s *zsetSchedulerService) ScheduledExpiry(taskID int64) (time.Time, bool, error) {
+	at, err := s.redisClient.ZScore(context.Background(), s.keys.schedule, expireKey(taskID)).Result()
+	if err == redis.Nil {
+		return time.Time{}, false, nil
+	}
+
+	if err != nil {
+		log.Println("Gagal membaca jadwal task:", err)
+		return time.Time{}, false, err
+	}
+
+	return time.UnixMilli(int64(at)), true, nil
+}
+
+// UpcomingExpirations membaca jadwal berdasarkan score per halaman karena reminder berada di sorted set yang sama
+func (s *zsetSchedulerService) UpcomingExpirations(from time.Time, to time.Time, limit int) ([]infra_scheduler.ScheduledExpiry, error) {
+	ctx := context.Background()
+	expirations := []infra_scheduler.ScheduledExpiry{}
+
+	page := int64(s.conf.BatchSize)
+	if page <= 0 {
+		page = 100
+	}
+
+	for offset := int64(0); ; offset += page {
+		members, err := s.redisClient.ZRangeByScoreWithScores(ctx, s.keys.schedule, &redis.ZRangeBy{
+			Min:    strconv.FormatFloat(score(from), 'f', -1, 64),
+			Max:    strconv.FormatFloat(score(to), 'f', -1, 64),
+			Offset: offset,
+			Count:  page,
+		}).Result()
+		if err != nil {
+			log.Println("Gagal membaca jadwal task:", err)
+			return nil, err
+		}
+
+		for _, member := range members {
+			key, ok := infra_scheduler.ParseKey(member.Member.(string))
+			if !ok || key.Kind != infra_scheduler.KindExpire {
+				continue
+			}
+
+			expirations = append(expirations, infra_scheduler.ScheduledExpiry{TaskID: key.TaskID, ExpiresAt: time.UnixMilli(int64(member.Score))})
+			if limit > 0 && len(expirations) >= limit {
+				return expirations, nil
+			}
+		}
+
+		if int64(len(members)) < page {
+			return expirations, nil
+		}
+	}
+}
+
+func (s *zsetSchedulerService) Status() infra_scheduler.WorkerStatus {
+	return s.state.Get()
+}
+
+// StartWorker mem-polling jadwal yang jatuh tempo setiap PollInterval sampai ctx dibatalkan.
+// Selama Redis gagal dihubungi jeda polling memakai backoff
+func (s *zsetSchedulerService) StartWorker(ctx context.Context) {
+	log.Println("Worker scheduler zset berjalan...")
+	defer s.state.Set(infra_scheduler.WorkerStopped)
+
+	backoff := &infra_scheduler.Backoff{Min: s.conf.PollInterval, Max: 30 * time.Second}
+	for {
+		wait := s.conf.PollInterval
+		if err := s.poll(ctx); err != nil {
+			s.state.Set(infra_scheduler.WorkerReconnecting)
+			wait = backoff.Next()
+			log.Printf("Gagal mengklaim jadwal task, dicoba lagi dalam %s: %v", wait, err)
+		} else {
+			s.state.Set(infra_scheduler.WorkerSubscribed)
+			backoff.Reset()
+		}
+
+		if !infra_scheduler.Sleep(ctx, wait) {
+			log.Println("Worker scheduler zset berhenti")
+			return
+		}
+	}
+}
+
+// poll mengklaim dan memproses jadwal jatuh tempo sampai habis
+func (s *zsetSchedulerService) poll(ctx context.Context) error {
+	for {
+		now := s.clock.Now()
+		members, err := claimScript.Run(ctx, s.redisClient, []string{s.keys.schedule, s.keys.processing},
+			now.UnixMilli(), s.conf.BatchSize, now.Add(s.conf.Lease).UnixMilli()).StringSlice()
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			s.process(ctx, member)
+		}
+
+		if len(members) < s.conf.BatchSize {
+			return nil
+		}
+	}
+}
+
+// process menjalankan satu jadwal. Jadwal yang gagal dibiarkan di set processing sehingga
+// diklaim ulang setelah lease habis, sampai MaxAttempts tercapai
+func (s *zsetSchedulerService) process(ctx context.Context, member string) {
+	key, ok := infra_scheduler.ParseKey(member)
+	if !ok {
+		s.ack(ctx, member)
+		return
+	}
+
+	err := infra_scheduler.Dispatch(s.handler, key)
+	if err == nil {
+		s.ack(ctx, member)
+		return
+	}
+
+	attempts, incrErr := s.redisClient.HIncrBy(ctx, s.keys.attempts, member, 1).Result()
+	if incrErr != nil {
+		log.Println("Gagal mencatat percobaan jadwal task:", incrErr)
+	}
+
+	if attempts >= int64(s.conf.MaxAttempts) {
+		log.Printf("Jadwal %s dibuang setelah %d percobaan: %+v", member, attempts, err)
+		s.ack(ctx, member)
+		return
+	}
+
+	log.Printf("Jadwal %s gagal diproses (percobaan %d), dicoba lagi setelah lease habis: %+v", member, attempts, err)
+}
+
+// ack menghapus jadwal dari set processing beserta catatan percobaannya
+func (s *zsetSchedulerService) ack(ctx context.Context, member string) {
+	pipe := s.redisClient.TxPipeline()
+	pipe.ZRem(ctx, s.keys.processing, member)
+	pipe.HDel(ctx, s.keys.attempts, member)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Println("Gagal menghapus jadwal task yang selesai:", err)
+	}
+}
+
+func (s *zsetSchedulerService) add(at time.Time, member string) error {
+	err := s.redisClient.ZAdd(context.Background(), s.keys.schedule, &redis.Z{Score: score(at), Member: member}).Err()
+	if err != nil {
+		log.Println("Gagal menjadwalkan task:", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *zsetSchedulerService) remove(members ...string) error {
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+
+	err := s.redisClient.ZRem(context.Background(), s.keys.schedule, values...).Err()
+	if err != nil {
+		log.Println("Gagal menghapus jadwal task:", err)
+		return err
+	}
+
+	return nil
+}
+
+// score mengubah waktu menjadi score sorted set (unix milidetik)
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
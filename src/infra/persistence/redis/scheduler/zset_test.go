@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo_list_consumer/src/infra/clock"
+	"todo_list_consumer/src/infra/config"
+	infra_scheduler "todo_list_consumer/src/infra/scheduler"
+	"todo_list_consumer/src/infra/scheduler/schedulertest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestZSet(t *testing.T, h infra_scheduler.TaskHandler) (*zsetSchedulerService, *miniredis.Miniredis, *clock.Fake) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	c := clock.NewFake(time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC))
+	s := NewZSetSchedulerService(client, c, config.SchedulerConf{
+		BatchSize:   10,
+		Lease:       time.Minute,
+		MaxAttempts: 2,
+	}).(*zsetSchedulerService)
+	s.RegisterHandler(h)
+
+	return s, mr, c
+}
+
+func TestZSetDueScheduleProcessedOnce(t *testing.T) {
+	h := &schedulertest.Handler{}
+	s, mr, c := newTestZSet(t, h)
+
+	assert.NoError(t, s.ScheduleTaskCancellation(1, c.Now().Add(time.Minute)))
+	assert.NoError(t, s.ScheduleTaskCancellation(2, c.Now().Add(time.Hour)))
+
+	// Jadwal yang jatuh tempo saat worker mati tetap ada di sorted set
+	c.Advance(10 * time.Minute)
+
+	assert.NoError(t, s.poll(context.Background()))
+	assert.NoError(t, s.poll(context.Background()))
+
+	assert.Equal(t, []int64{1}, h.Expired())
+
+	members, _ := mr.ZMembers(s.keys.schedule)
+	assert.Equal(t, []string{expireKey(2)}, members)
+	assert.False(t, mr.Exists(s.keys.processing))
+}
+
+func TestZSetFailedScheduleRetriedAfterLease(t *testing.T) {
+	h := &schedulertest.Handler{}
+	h.FailWith(errors.New("db down"), -1)
+	s, mr, c := newTestZSet(t, h)
+
+	assert.NoError(t, s.ScheduleTaskCancellation(1, c.Now().Add(time.Minute)))
+	c.Advance(time.Minute)
+
+	assert.NoError(t, s.poll(context.Background()))
+	assert.Equal(t, []int64{1}, h.Expired())
+
+	// Lease belum habis, jadwal tidak diklaim ulang
+	assert.NoError(t, s.poll(context.Background()))
+	assert.Equal(t, []int64{1}, h.Expired())
+
+	// Lease habis, jadwal kembali ke antrian lalu dibuang setelah MaxAttempts
+	c.Advance(time.Minute)
+	assert.NoError(t, s.poll(context.Background()))
+	assert.Equal(t, []int64{1, 1}, h.Expired())
+
+	assert.False(t, mr.Exists(s.keys.schedule))
+	assert.False(t, mr.Exists(s.keys.processing))
+	assert.False(t, mr.Exists(s.keys.attempts))
+}
+
+func TestZSetUpcomingExpirationsSkipsReminders(t *testing.T) {
+	s, _, c := newTestZSet(t, &schedulertest.Handler{})
+	now := c.Now()
+
+	for id := int64(1); id <= 12; id++ {
+		deadline := now.Add(time.Duration(id) * time.Minute)
+		assert.NoError(t, s.ScheduleTaskCancellation(id, deadline))
+		assert.NoError(t, s.ScheduleTaskReminders(id, deadline, []time.Duration{30 * time.Second}))
+	}
+
+	// Halaman pertama berisi reminder dan expire sehingga perlu membaca lebih dari satu halaman
+	upcoming, err := s.UpcomingExpirations(now, now.Add(time.Hour), 8)
+	assert.NoError(t, err)
+	if assert.Len(t, upcoming, 8) {
+		assert.Equal(t, int64(1), upcoming[0].TaskID)
+		assert.True(t, now.Add(time.Minute).Equal(upcoming[0].ExpiresAt))
+		assert.Equal(t, int64(8), upcoming[7].TaskID)
+	}
+
+	at, ok, err := s.ScheduledExpiry(3)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, now.Add(3*time.Minute).Equal(at))
+
+	_, ok, _ = s.ScheduledExpiry(99)
+	assert.False(t, ok)
+}
+
+func TestZSetCancelRemindersFromOldOffsets(t *testing.T) {
+	s, mr, c := newTestZSet(t, &schedulertest.Handler{})
+	deadline := c.Now().Add(3 * time.Hour)
+
+	assert.NoError(t, s.ScheduleTaskReminders(1, deadline, []time.Duration{time.Hour, 2 * time.Hour}))
+	assert.NoError(t, s.ScheduleTaskReminders(2, deadline, []time.Duration{time.Hour}))
+
+	ids, err := s.ReminderTaskIDs()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2}, ids)
+
+	assert.NoError(t, s.CancelTaskReminders(1))
+	ids, _ = s.ReminderTaskIDs()
+	assert.Equal(t, []int64{2}, ids)
+
+	members, err := mr.ZMembers(s.keys.schedule)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{remindKey(2, time.Hour)}, members)
+	assert.False(t, mr.Exists(s.keys.reminders(1)))
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// Jenis jadwal task, dipakai semua backend scheduler
+const (
+	KindExpire = "expire" // task:<id>:expire
+	KindRemind = "remind" // task:<id>:remind:<offset dalam detik>
+)
+
+// ScheduledKey adalah satu jadwal task yang jatuh tempo
+type ScheduledKey struct {
+	Kind   string
+	TaskID int64
+	Offset time.Duration // hanya terisi untuk KindRemind
+}
+
+// Member mengembalikan nama jadwal tanpa namespace, contoh task:42:expire. Dipakai sebagai key Redis
+// dan sebagai MessageID riwayat
+func (k ScheduledKey) Member() string {
+	if k.Kind == KindRemind {
+		return fmt.Sprintf("task:%d:remind:%d", k.TaskID, int64(k.Offset/time.Second))
+	}
+
+	return fmt.Sprintf("task:%d:expire", k.TaskID)
+}
+
+// ParseKey mengenali nama jadwal task, nama lain diabaikan (ok = false)
+func ParseKey(member string) (ScheduledKey, bool) {
+	var parsed ScheduledKey
+	var seconds int64
+
+	if n, _ := fmt.Sscanf(member, "task:%d:remind:%d", &parsed.TaskID, &seconds); n == 2 {
+		parsed.Kind = KindRemind
+		parsed.Offset = time.Duration(seconds) * time.Second
+		if parsed.Member() == member {
+			return parsed, true
+		}
+	}
+
+	parsed = ScheduledKey{}
+	if n, _ := fmt.Sscanf(member, "task:%d:expire", &parsed.TaskID); n == 1 {
+		parsed.Kind = KindExpire
+		if parsed.Member() == member {
+			return parsed, true
+		}
+	}
+
+	return ScheduledKey{}, false
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	taskConst "todo_list_consumer/src/infra/constants"
+)
+
+// Interface untuk scheduler booking. Diimplementasikan oleh backend keyspace, zset, postgres dan memory
+type SchedulerInterface interface {
+	ScheduleTaskCancellation(taskID int64, expiresAt time.Time) error
+	ExtendTaskCancellation(taskID int64, expiresAt time.Time) error
+	CancelTaskCancellation(taskID int64) error
+	ScheduleTaskReminders(taskID int64, expiresAt time.Time, offsets []time.Duration) error
+	CancelTaskReminders(taskID int64) error // Menghapus semua reminder task, termasuk yang dibuat dengan offset lama
+	ScheduledTaskIDs() ([]int64, error)     // ID task yang memiliki jadwal expire, dipakai reconciler
+	ReminderTaskIDs() ([]int64, error)      // ID task yang memiliki jadwal reminder, dipakai reconciler
+	RegisterHandler(h TaskHandler)          // Mendaftarkan handler yang dipanggil saat task jatuh tempo
+	StartWorker(ctx context.Context)        // Menjalankan worker sampai ctx dibatalkan
+	Status() WorkerStatus                   // Status worker untuk health check
+
+	// ScheduledExpiry mengembalikan waktu jadwal expire task, false jika task tidak memiliki jadwal
+	ScheduledExpiry(taskID int64) (time.Time, bool, error)
+	// UpcomingExpirations mengembalikan jadwal expire antara from dan to, urut dari yang paling dekat
+	UpcomingExpirations(from time.Time, to time.Time, limit int) ([]ScheduledExpiry, error)
+}
+
+// ScheduledExpiry adalah satu jadwal expire menurut scheduler
+type ScheduledExpiry struct {
+	TaskID    int64     `db:"task_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// TaskHandler dipanggil worker ketika jadwal sebuah task jatuh tempo.
+// Diimplementasikan oleh use case task
+type TaskHandler interface {
+	ExpireTask(req *dto.ExpireTaskReqDTO) error
+	RemindTask(req *dto.RemindTaskReqDTO) error
+}
+
+// Dispatch memanggil handler sesuai jenis jadwal. Dipakai bersama oleh semua backend scheduler
+func Dispatch(h TaskHandler, key ScheduledKey) error {
+	// MessageID memakai nama jadwal agar riwayat bisa ditelusuri ke jadwal yang memicunya
+	meta := dto.EventMetaDTO{Source: taskConst.SOURCE_SCHEDULER, MessageID: key.Member()}
+
+	switch key.Kind {
+	case KindRemind:
+		return h.RemindTask(&dto.RemindTaskReqDTO{ID: key.TaskID, Offset: key.Offset, Meta: meta})
+	case KindExpire:
+		log.Printf("Memproses deadline task ID %d", key.TaskID)
+		return h.ExpireTask(&dto.ExpireTaskReqDTO{ID: key.TaskID, Meta: meta})
+	}
+
+	return nil
+}
+
+// SortExpirations mengurutkan jadwal dari yang paling dekat lalu memotongnya sampai limit
+func SortExpirations(expirations []ScheduledExpiry, limit int) []ScheduledExpiry {
+	sort.Slice(expirations, func(i, j int) bool {
+		if expirations[i].ExpiresAt.Equal(expirations[j].ExpiresAt) {
+			return expirations[i].TaskID < expirations[j].TaskID
+		}
+
+		return expirations[i].ExpiresAt.Before(expirations[j].ExpiresAt)
+	})
+
+	if limit > 0 && len(expirations) > limit {
+		expirations = expirations[:limit]
+	}
+
+	return expirations
+}
@@ -0,0 +1,85 @@
+// Package schedulertest berisi TaskHandler palsu yang dipakai bersama oleh test backend scheduler
+package schedulertest
+
+import (
+	"strconv"
+	"sync"
+
+	dto "todo_list_consumer/src/app/dto/task"
+)
+
+// Handler mencatat setiap jadwal yang di-dispatch worker. Aman dibaca dari goroutine test selama
+// worker berjalan
+type Handler struct {
+	mu      sync.Mutex
+	calls   []string // urutan pemanggilan, contoh "expire:1" atau "remind:1:10m0s"
+	expired []int64
+	metas   []dto.EventMetaDTO // meta setiap dispatch, expire maupun reminder
+	err     error
+	fails   int // sisa pemanggilan yang gagal, negatif berarti selalu gagal
+}
+
+// FailWith membuat times pemanggilan berikutnya mengembalikan err, times negatif berarti selalu gagal
+func (h *Handler) FailWith(err error, times int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.err = err
+	h.fails = times
+}
+
+func (h *Handler) ExpireTask(req *dto.ExpireTaskReqDTO) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.calls = append(h.calls, "expire:"+strconv.FormatInt(req.ID, 10))
+	h.expired = append(h.expired, req.ID)
+	h.metas = append(h.metas, req.Meta)
+	return h.result()
+}
+
+func (h *Handler) RemindTask(req *dto.RemindTaskReqDTO) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.calls = append(h.calls, "remind:"+strconv.FormatInt(req.ID, 10)+":"+req.Offset.String())
+	h.metas = append(h.metas, req.Meta)
+	return h.result()
+}
+
+// Calls mengembalikan salinan urutan pemanggilan
+func (h *Handler) Calls() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]string{}, h.calls...)
+}
+
+// Expired mengembalikan salinan ID task yang di-expire, termasuk pemanggilan yang gagal
+func (h *Handler) Expired() []int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]int64{}, h.expired...)
+}
+
+// Metas mengembalikan salinan meta setiap dispatch
+func (h *Handler) Metas() []dto.EventMetaDTO {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]dto.EventMetaDTO{}, h.metas...)
+}
+
+// result mengembalikan error pemanggilan saat ini sesuai FailWith, dipanggil dengan mu terkunci
+func (h *Handler) result() error {
+	if h.fails == 0 {
+		return nil
+	}
+
+	if h.fails > 0 {
+		h.fails--
+	}
+
+	return h.err
+}
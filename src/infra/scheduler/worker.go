@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// WorkerStatus adalah status worker scheduler yang dibaca health check
+type WorkerStatus string
+
+const (
+	WorkerStopped      WorkerStatus = "stopped"      // worker belum dijalankan atau sudah berhenti
+	WorkerSubscribed   WorkerStatus = "subscribed"   // worker terhubung dan menerima jadwal
+	WorkerReconnecting WorkerStatus = "reconnecting" // koneksi gagal, worker mencoba lagi dengan backoff
+)
+
+// WorkerState menyimpan status worker agar aman dibaca dari goroutine lain
+type WorkerState struct {
+	status atomic.Value
+}
+
+// Set mengganti status worker
+func (s *WorkerState) Set(status WorkerStatus) {
+	s.status.Store(status)
+}
+
+// Get mengembalikan status worker, stopped jika worker belum pernah dijalankan
+func (s *WorkerState) Get() WorkerStatus {
+	if status, ok := s.status.Load().(WorkerStatus); ok {
+		return status
+	}
+
+	return WorkerStopped
+}
+
+// Backoff menghitung jeda percobaan ulang yang berlipat dua dari Min sampai Max
+type Backoff struct {
+	Min     time.Duration
+	Max     time.Duration
+	current time.Duration
+}
+
+// Next mengembalikan jeda berikutnya
+func (b *Backoff) Next() time.Duration {
+	if b.current == 0 {
+		b.current = b.Min
+		return b.current
+	}
+
+	b.current *= 2
+	if b.current > b.Max {
+		b.current = b.Max
+	}
+
+	return b.current
+}
+
+// Reset mengembalikan jeda ke Min setelah koneksi berhasil
+func (b *Backoff) Reset() {
+	b.current = 0
+}
+
+// Sleep menunggu selama d, false jika ctx dibatalkan lebih dulu
+func Sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
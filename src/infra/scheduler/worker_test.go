@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffDoublesUntilMaxAndResets(t *testing.T) {
+	b := &Backoff{Min: time.Second, Max: 5 * time.Second}
+
+	assert.Equal(t, time.Second, b.Next())
+	assert.Equal(t, 2*time.Second, b.Next())
+	assert.Equal(t, 4*time.Second, b.Next())
+	assert.Equal(t, 5*time.Second, b.Next())
+	assert.Equal(t, 5*time.Second, b.Next())
+
+	b.Reset()
+	assert.Equal(t, time.Second, b.Next())
+}
+
+func TestWorkerStateDefaultsToStopped(t *testing.T) {
+	var s WorkerState
+	assert.Equal(t, WorkerStopped, s.Get())
+
+	s.Set(WorkerSubscribed)
+	assert.Equal(t, WorkerSubscribed, s.Get())
+}
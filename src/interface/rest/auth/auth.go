@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	infra_errors "todo_list_consumer/src/infra/errors"
+	"todo_list_consumer/src/interface/rest/response"
+)
+
+type contextKey string
+
+const userIDKey contextKey = "user_id"
+
+// User membaca id user yang sudah diautentikasi API gateway dari header lalu menyimpannya di context
+// request. Request tanpa header atau dengan id tidak valid ditolak dengan 401
+func User(header string, resp response.IResponseClient) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := strconv.ParseInt(r.Header.Get(header), 10, 64)
+			if err != nil || userID <= 0 {
+				resp.HttpError(w, infra_errors.NewError(infra_errors.UNAUTHORIZED, errors.New("header "+header+" tidak valid")))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userIDKey, userID)))
+		})
+	}
+}
+
+// UserID mengembalikan id user pemanggil yang disimpan middleware User, 0 jika tidak ada
+func UserID(ctx context.Context) int64 {
+	userID, _ := ctx.Value(userIDKey).(int64)
+	return userID
+}
+
+// Admin menolak request yang tidak membawa header Authorization: Bearer <token> dengan token admin
+// yang dikonfigurasi. Token dibandingkan dengan waktu konstan agar tidak bisa ditebak dari waktu respons
+func Admin(token string, resp response.IResponseClient) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				resp.HttpError(w, infra_errors.NewError(infra_errors.UNAUTHORIZED, errors.New("token admin tidak valid")))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
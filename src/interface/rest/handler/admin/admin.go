@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	useCase "todo_list_consumer/src/app/usecases/task"
+	"todo_list_consumer/src/infra/clock"
+	taskConst "todo_list_consumer/src/infra/constants"
+	infra_errors "todo_list_consumer/src/infra/errors"
+	"todo_list_consumer/src/interface/rest/response"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type IAdminHandler interface {
+	GetClock(w http.ResponseWriter, r *http.Request)
+	SetClock(w http.ResponseWriter, r *http.Request)
+	GetTaskSchedule(w http.ResponseWriter, r *http.Request)
+	ListSchedules(w http.ResponseWriter, r *http.Request)
+	ForceExpireTask(w http.ResponseWriter, r *http.Request)
+	CancelTaskExpiry(w http.ResponseWriter, r *http.Request)
+	RescheduleTask(w http.ResponseWriter, r *http.Request)
+}
+
+type adminHandler struct {
+	response   response.IResponseClient
+	useCase    useCase.TaskUseCase
+	timeTravel *clock.Offset // nil jika APP_TIME_TRAVEL tidak aktif
+}
+
+func NewAdminHandler(r response.IResponseClient, uc useCase.TaskUseCase, timeTravel *clock.Offset) IAdminHandler {
+	return &adminHandler{
+		response:   r,
+		useCase:    uc,
+		timeTravel: timeTravel,
+	}
+}
+
+// clockDTO adalah waktu service saat ini beserta pergeserannya dari waktu sebenarnya
+type clockDTO struct {
+	Now    time.Time `json:"now"`
+	Offset string    `json:"offset"`
+}
+
+// setClockReqDTO berisi pergeseran waktu dalam format durasi Go, contoh "2h", "-30m" atau "0s" untuk reset
+type setClockReqDTO struct {
+	Offset string `json:"offset"`
+}
+
+// rescheduleReqDTO berisi deadline baru task berformat RFC3339
+type rescheduleReqDTO struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// adminMeta menandai perubahan task lewat endpoint admin di riwayat task
+var adminMeta = dto.EventMetaDTO{Source: taskConst.SOURCE_ADMIN}
+
+// GetClock mengembalikan waktu yang sedang dipakai service
+func (h *adminHandler) GetClock(w http.ResponseWriter, r *http.Request) {
+	h.response.JSON(w, "Success", h.clock(), nil)
+}
+
+// SetClock menggeser waktu service. Berlaku untuk use case, repository dan scheduler polling
+// (zset, postgres, memory). Key Redis backend keyspace yang sudah ada tetap mengikuti waktu Redis
+func (h *adminHandler) SetClock(w http.ResponseWriter, r *http.Request) {
+	var req setClockReqDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.response.HttpError(w, infra_errors.NewError(infra_errors.DATA_INVALID, err))
+		return
+	}
+
+	offset, err := time.ParseDuration(req.Offset)
+	if err != nil {
+		h.response.HttpError(w, infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("offset harus berupa durasi, contoh 2h atau -30m")))
+		return
+	}
+
+	h.timeTravel.SetOffset(offset)
+	log.Printf("Waktu service digeser %s dari waktu sebenarnya", offset)
+
+	h.response.JSON(w, "Success", h.clock(), nil)
+}
+
+func (h *adminHandler) clock() clockDTO {
+	return clockDTO{
+		Now:    h.timeTravel.Now(),
+		Offset: h.timeTravel.Offset().String(),
+	}
+}
+
+// GetTaskSchedule mengembalikan deadline task beserta jadwal expire dan sisa TTL-nya di scheduler
+func (h *adminHandler) GetTaskSchedule(w http.ResponseWriter, r *http.Request) {
+	taskID, err := taskIDParam(r)
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	schedule, err := h.useCase.GetTaskSchedule(taskID)
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	h.response.JSON(w, "Success", schedule, nil)
+}
+
+// ListSchedules mengembalikan jadwal expire terdekat, query from dan to berformat RFC3339
+func (h *adminHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	req := &dto.UpcomingExpirationsReqDTO{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			h.response.HttpError(w, infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("limit harus berupa angka")))
+			return
+		}
+
+		req.Limit = parsed
+	}
+
+	schedules, err := h.useCase.ListUpcomingExpirations(req)
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	h.response.JSON(w, "Success", schedules, nil)
+}
+
+// ForceExpireTask meng-expire task pending saat ini juga
+func (h *adminHandler) ForceExpireTask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := taskIDParam(r)
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	if err := h.useCase.ForceExpireTask(&dto.ExpireTaskReqDTO{ID: taskID, Meta: adminMeta}); err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	h.response.JSON(w, "Success", nil, nil)
+}
+
+// CancelTaskExpiry menghapus jadwal expire task sehingga task tetap pending
+func (h *adminHandler) CancelTaskExpiry(w http.ResponseWriter, r *http.Request) {
+	taskID, err := taskIDParam(r)
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	if err := h.useCase.CancelTaskExpiry(&dto.CancelExpiryReqDTO{ID: taskID, Meta: adminMeta}); err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	h.response.JSON(w, "Success", nil, nil)
+}
+
+// RescheduleTask memindahkan deadline task ke expires_at di body lalu mengembalikan jadwal barunya
+func (h *adminHandler) RescheduleTask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := taskIDParam(r)
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	var req rescheduleReqDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.response.HttpError(w, infra_errors.NewError(infra_errors.DATA_INVALID, err))
+		return
+	}
+
+	err = h.useCase.RescheduleTask(&dto.RescheduleTaskReqDTO{ID: taskID, ExpiresAt: req.ExpiresAt, Meta: adminMeta})
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	h.GetTaskSchedule(w, r)
+}
+
+// taskIDParam membaca parameter {id} dari URL
+func taskIDParam(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("id task tidak valid"))
+	}
+
+	return id, nil
+}
@@ -1,25 +1,60 @@
 package health
 
 import (
+	"errors"
 	"net/http"
+	"sort"
+	"strings"
 
+	infra_errors "todo_list_consumer/src/infra/errors"
 	"todo_list_consumer/src/interface/rest/response"
 )
 
 type IHealthHandler interface {
 	Ping(w http.ResponseWriter, r *http.Request)
+	Ready(w http.ResponseWriter, r *http.Request)
 }
 
 type healthHandler struct {
 	response response.IResponseClient
+	checks   map[string]func() error // pengecekan readiness per dependency, error berarti belum siap
 }
 
-func NewHealthHandler(r response.IResponseClient) IHealthHandler {
+func NewHealthHandler(r response.IResponseClient, checks map[string]func() error) IHealthHandler {
 	return &healthHandler{
 		response: r,
+		checks:   checks,
 	}
 }
 
 func (h *healthHandler) Ping(w http.ResponseWriter, r *http.Request) {
 	h.response.JSON(w, "Pong", nil, nil)
 }
+
+// Ready menjalankan semua pengecekan readiness, 503 jika ada yang gagal
+func (h *healthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	status := map[string]string{}
+	failed := []string{}
+	for _, name := range names {
+		if err := h.checks[name](); err != nil {
+			status[name] = err.Error()
+			failed = append(failed, name+": "+err.Error())
+			continue
+		}
+
+		status[name] = "ok"
+	}
+
+	if len(failed) > 0 {
+		h.response.HttpError(w, infra_errors.NewError(infra_errors.SERVICE_NOT_READY, errors.New(strings.Join(failed, "; "))))
+		return
+	}
+
+	h.response.JSON(w, "Ready", status, nil)
+}
@@ -0,0 +1,83 @@
+package task
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	dto "todo_list_consumer/src/app/dto/task"
+	useCase "todo_list_consumer/src/app/usecases/task"
+	infra_errors "todo_list_consumer/src/infra/errors"
+	"todo_list_consumer/src/interface/rest/auth"
+	"todo_list_consumer/src/interface/rest/response"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ITaskHandler interface {
+	Timeline(w http.ResponseWriter, r *http.Request)
+	TimeReport(w http.ResponseWriter, r *http.Request)
+}
+
+type taskHandler struct {
+	response response.IResponseClient
+	useCase  useCase.TaskUseCase
+}
+
+func NewTaskHandler(r response.IResponseClient, uc useCase.TaskUseCase) ITaskHandler {
+	return &taskHandler{
+		response: r,
+		useCase:  uc,
+	}
+}
+
+// Timeline mengembalikan riwayat perubahan task urut dari yang paling lama, hanya untuk user yang
+// boleh melihat task tersebut
+func (h *taskHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	taskID, err := taskIDParam(r)
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	history, err := h.useCase.GetTaskTimeline(&dto.TimelineReqDTO{ID: taskID, UserID: auth.UserID(r.Context())})
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	h.response.JSON(w, "Success", history, nil)
+}
+
+// TimeReport mengembalikan total waktu kerja user per hari, query from dan to berformat 2006-01-02.
+// Hanya user itu sendiri yang boleh membaca laporannya
+func (h *taskHandler) TimeReport(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || userID <= 0 {
+		h.response.HttpError(w, infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("id user tidak valid")))
+		return
+	}
+
+	report, err := h.useCase.GetTimeReport(&dto.TimeReportReqDTO{
+		UserID:      userID,
+		RequesterID: auth.UserID(r.Context()),
+		From:        r.URL.Query().Get("from"),
+		To:          r.URL.Query().Get("to"),
+	})
+	if err != nil {
+		h.response.HttpError(w, err)
+		return
+	}
+
+	h.response.JSON(w, "Success", report, nil)
+}
+
+// taskIDParam membaca parameter {id} dari URL
+func taskIDParam(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, infra_errors.NewError(infra_errors.DATA_INVALID, errors.New("id task tidak valid"))
+	}
+
+	return id, nil
+}
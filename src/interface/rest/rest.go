@@ -2,16 +2,18 @@ package rest
 
 import (
 	"context"
+	"expvar"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	usecases "todo_list_consumer/src/app/usecases"
+	"todo_list_consumer/src/infra/clock"
 	"todo_list_consumer/src/infra/config"
 
+	"todo_list_consumer/src/interface/rest/auth"
+	adminHandler "todo_list_consumer/src/interface/rest/handler/admin"
 	healthHandler "todo_list_consumer/src/interface/rest/handler/health"
+	taskHandler "todo_list_consumer/src/interface/rest/handler/task"
 	"todo_list_consumer/src/interface/rest/response"
 	"todo_list_consumer/src/interface/rest/route"
 
@@ -24,7 +26,8 @@ import (
 // HttpServer holds the dependencies for a HTTP server.
 type HttpServer struct {
 	*http.Server
-	logger *logrus.Logger
+	internal *http.Server // endpoint internal seperti /debug/vars di port terpisah
+	logger   *logrus.Logger
 }
 
 // New creates and configures a server serving all application routes.
@@ -36,9 +39,11 @@ func New(
 	isProd bool,
 	logger *logrus.Logger,
 	useCases usecases.AllUseCases,
+	timeTravel *clock.Offset,
+	readinessChecks map[string]func() error,
 ) (*HttpServer, error) {
 	// wrap all the routes
-	routeHandler := makeRoute(conf.XRequestID, conf.Timeout, isProd, logger, useCases)
+	routeHandler := makeRoute(conf.XRequestID, conf.Timeout, conf.UserIDHeader, conf.AdminToken, isProd, logger, useCases, timeTravel, readinessChecks)
 
 	// http service
 	srv := http.Server{
@@ -46,16 +51,30 @@ func New(
 		Handler: routeHandler,
 	}
 
-	return &HttpServer{&srv, logger}, nil
+	// endpoint internal tidak ikut router publik agar counter operasional tidak terbuka
+	internalRoute := chi.NewRouter()
+	internalRoute.Use(middleware.Recoverer)
+	internalRoute.Handle("/debug/vars", expvar.Handler())
+
+	internal := http.Server{
+		Addr:    ":" + conf.InternalPort,
+		Handler: internalRoute,
+	}
+
+	return &HttpServer{&srv, &internal, logger}, nil
 }
 
 // makeRoute register routes
 func makeRoute(
 	xRequestID string,
 	timeout int,
+	userIDHeader string,
+	adminToken string,
 	isProd bool,
 	logger *logrus.Logger,
 	useCases usecases.AllUseCases,
+	timeTravel *clock.Offset,
+	readinessChecks map[string]func() error,
 ) *chi.Mux {
 
 	r := chi.NewRouter()
@@ -73,7 +92,7 @@ func makeRoute(
 	corsMiddleware := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Accept", "Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Accept", "Content-Type", "Authorization", userIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
@@ -92,13 +111,29 @@ func makeRoute(
 
 	// instantiate the handlers here ...
 	respClient := response.NewResponseClient()
-	hh := healthHandler.NewHealthHandler(respClient)
+	hh := healthHandler.NewHealthHandler(respClient, readinessChecks)
 	r.Mount("/", route.HealthRouter(hh))
 
+	// Endpoint data task dan user hanya untuk user yang sudah diautentikasi API gateway
+	userAuth := auth.User(userIDHeader, respClient)
+	th := taskHandler.NewTaskHandler(respClient, useCases.TaskUC)
+	r.With(userAuth).Mount("/tasks", route.TaskRouter(th))
+	r.With(userAuth).Mount("/users", route.UserRouter(th))
+
+	// Endpoint admin bisa memaksa expire dan membatalkan jadwal task siapa saja, hanya didaftarkan
+	// jika token admin dikonfigurasi
+	if adminToken != "" {
+		ah := adminHandler.NewAdminHandler(respClient, useCases.TaskUC, timeTravel)
+		r.With(auth.Admin(adminToken, respClient)).Mount("/admin", route.AdminRouter(ah, timeTravel != nil))
+	} else {
+		logger.Warn("HTTP_ADMIN_TOKEN is empty, /admin endpoints are disabled")
+	}
+
 	return r
 }
 
-// Start runs ListenAndServe on the http.Server with graceful shutdown
+// Start runs ListenAndServe on the http.Server and shuts it down gracefully once ctx is cancelled,
+// main cancels ctx on SIGINT/SIGTERM
 func (srv *HttpServer) Start(ctx context.Context) {
 	// run HTTP service
 	go func() {
@@ -107,27 +142,27 @@ func (srv *HttpServer) Start(ctx context.Context) {
 		}
 	}()
 
+	go func() {
+		if err := srv.internal.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			srv.logger.Fatal(err)
+		}
+	}()
+
 	// ready to serve
 	srv.logger.Info("listen on", srv.Addr)
+	srv.logger.Info("internal endpoint listen on", srv.internal.Addr)
 
 	srv.gracefulShutdown(ctx)
 }
 
 func (srv *HttpServer) gracefulShutdown(ctx context.Context) {
-	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 5 seconds.
-	quit := make(chan os.Signal, 1)
-
-	// kill (no param) default send syscall.SIGTERM
-	// kill -2 is syscall.SIGINT
-	// kill -9 is syscall.SIGKILL but can't be catch, so don't need add it
-	signal.Notify(quit, os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-
-	// wait forever until 1 of signals above are received
-	<-quit
-	srv.logger.Warnf("got signal: %v, shutting down server ...", quit)
+	// Wait until the service is stopped, then gracefully shutdown the server with
+	// a timeout of 5 seconds
+	<-ctx.Done()
+	srv.logger.Warn("shutting down server ...")
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	// ctx sudah dibatalkan, batas waktu shutdown dihitung dari context baru
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	srv.SetKeepAlivesEnabled(false)
@@ -135,5 +170,9 @@ func (srv *HttpServer) gracefulShutdown(ctx context.Context) {
 		srv.logger.Error(err)
 	}
 
+	if err := srv.internal.Shutdown(ctx); err != nil {
+		srv.logger.Error(err)
+	}
+
 	srv.logger.Println("server exiting")
 }
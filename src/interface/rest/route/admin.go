@@ -0,0 +1,28 @@
+package route
+
+import (
+	"net/http"
+
+	handlers "todo_list_consumer/src/interface/rest/handler/admin"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AdminRouter route untuk operasi admin. Endpoint time travel hanya didaftarkan jika timeTravel aktif
+func AdminRouter(h handlers.IAdminHandler, timeTravel bool) http.Handler {
+	r := chi.NewRouter()
+
+	// Inspeksi dan kontrol jadwal expire task di scheduler
+	r.Get("/schedules", h.ListSchedules)
+	r.Get("/tasks/{id}/schedule", h.GetTaskSchedule)
+	r.Put("/tasks/{id}/schedule", h.RescheduleTask)
+	r.Delete("/tasks/{id}/schedule", h.CancelTaskExpiry)
+	r.Post("/tasks/{id}/expire", h.ForceExpireTask)
+
+	if timeTravel {
+		r.Get("/clock", h.GetClock)
+		r.Put("/clock", h.SetClock)
+	}
+
+	return r
+}
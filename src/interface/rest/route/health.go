@@ -13,6 +13,7 @@ func HealthRouter(h handlers.IHealthHandler) http.Handler {
 	r := chi.NewRouter()
 
 	r.Get("/ping", h.Ping)
+	r.Get("/ready", h.Ready)
 
 	return r
 }
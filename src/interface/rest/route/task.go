@@ -0,0 +1,18 @@
+package route
+
+import (
+	"net/http"
+
+	handlers "todo_list_consumer/src/interface/rest/handler/task"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TaskRouter route untuk membaca data task
+func TaskRouter(h handlers.ITaskHandler) http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/{id}/timeline", h.Timeline)
+
+	return r
+}
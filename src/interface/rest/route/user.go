@@ -0,0 +1,18 @@
+package route
+
+import (
+	"net/http"
+
+	handlers "todo_list_consumer/src/interface/rest/handler/task"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UserRouter route untuk laporan per user
+func UserRouter(h handlers.ITaskHandler) http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/{id}/time-report", h.TimeReport)
+
+	return r
+}